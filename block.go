@@ -0,0 +1,234 @@
+package goudev
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+const (
+	sectorSize = 512 // unit of the block "size" attribute, independent of the device
+)
+
+var (
+	ErrNotBlockDevice = errors.New("udev: device is not in the block subsystem")
+)
+
+// BlockDevice is a typed view of a block subsystem Device (disk or partition)
+type BlockDevice struct {
+	*Device
+}
+
+func NewBlockDevice(d *Device) (*BlockDevice, error) {
+	if d.Subsystem() != "block" {
+		return nil, ErrNotBlockDevice
+	}
+
+	return &BlockDevice{d}, nil
+}
+
+func (b *BlockDevice) IsDisk() bool {
+	return b.DeviceType() == "disk"
+}
+
+func (b *BlockDevice) IsPartition() bool {
+	return b.DeviceType() == "partition"
+}
+
+// queueDevice returns the device owning queue/ and removable, the disk for a
+// partition; release drops the reference taken for the disk
+func (b *BlockDevice) queueDevice() (d *Device, release func()) {
+	if b.IsPartition() {
+		if p, err := b.Disk(); err == nil {
+			return p.Device, p.Free
+		}
+	}
+
+	return b.Device, func() {}
+}
+
+// queueAttribute reads attribute of the queueDevice, trimmed
+func (b *BlockDevice) queueAttribute(attribute string) string {
+	d, release := b.queueDevice()
+	defer release()
+
+	return strings.TrimSpace(d.GetAttribute(attribute))
+}
+
+// Size in bytes
+func (b *BlockDevice) Size() uint64 {
+	n, _ := b.attributeUint64("size")
+	return n * sectorSize
+}
+
+func (b *BlockDevice) LogicalBlockSize() uint64 {
+	n, _ := parseUint64(b.queueAttribute("queue/logical_block_size"))
+	return n
+}
+
+func (b *BlockDevice) PhysicalBlockSize() uint64 {
+	n, _ := parseUint64(b.queueAttribute("queue/physical_block_size"))
+	return n
+}
+
+func (b *BlockDevice) MinimumIOSize() uint64 {
+	n, _ := parseUint64(b.queueAttribute("queue/minimum_io_size"))
+	return n
+}
+
+func (b *BlockDevice) OptimalIOSize() uint64 {
+	n, _ := parseUint64(b.queueAttribute("queue/optimal_io_size"))
+	return n
+}
+
+func (b *BlockDevice) Rotational() bool {
+	return b.queueAttribute("queue/rotational") == "1"
+}
+
+func (b *BlockDevice) ReadOnly() bool {
+	return b.attributeBool("ro")
+}
+
+func (b *BlockDevice) Removable() bool {
+	return b.queueAttribute("removable") == "1"
+}
+
+// PartitionNumber is 0 for disks
+func (b *BlockDevice) PartitionNumber() int {
+	n, _ := b.attributeInt("partition")
+	return n
+}
+
+// Transport like lsblk TRAN: nvme, sata, sas, usb, scsi, virtio, mmc, ata, fc, iscsi
+func (b *BlockDevice) Transport() string {
+	d, release := b.queueDevice()
+	defer release()
+	devpath := d.DevicePath()
+
+	switch {
+	case strings.Contains(devpath, "/usb"):
+		return "usb"
+	case strings.HasPrefix(d.SysName(), "nvme"):
+		return "nvme"
+	case strings.HasPrefix(d.SysName(), "mmcblk"):
+		return "mmc"
+	case strings.Contains(devpath, "/virtio") && strings.HasPrefix(d.SysName(), "vd"):
+		return "virtio"
+	case strings.Contains(devpath, "/ata"):
+		if d.Get("ID_ATA_SATA") == "1" || strings.Contains(devpath, "/ahci") {
+			return "sata"
+		}
+		return "ata"
+	case strings.Contains(devpath, "/session"):
+		return "iscsi"
+	case strings.Contains(devpath, "/rport-"):
+		return "fc"
+	case strings.Contains(devpath, "/port-") || strings.Contains(devpath, "/end_device-"):
+		return "sas"
+	case strings.Contains(devpath, "/target"):
+		return "scsi"
+	}
+
+	return ""
+}
+
+func (b *BlockDevice) Serial() string {
+	if v := b.Get("ID_SERIAL_SHORT"); v != "" {
+		return v
+	}
+	if v := b.Get("ID_SCSI_SERIAL"); v != "" {
+		return v
+	}
+
+	return b.queueAttribute("device/serial")
+}
+
+func (b *BlockDevice) WWN() string {
+	if v := b.Get("ID_WWN_WITH_EXTENSION"); v != "" {
+		return v
+	}
+	if v := b.Get("ID_WWN"); v != "" {
+		return v
+	}
+
+	return b.queueAttribute("wwid")
+}
+
+func (b *BlockDevice) Model() string {
+	if v := b.Get("ID_MODEL"); v != "" {
+		return v
+	}
+
+	return b.queueAttribute("device/model")
+}
+
+func (b *BlockDevice) Vendor() string {
+	if v := b.Get("ID_VENDOR"); v != "" {
+		return v
+	}
+
+	return b.queueAttribute("device/vendor")
+}
+
+// PartitionTableType is gpt, dos, ...
+func (b *BlockDevice) PartitionTableType() string {
+	return b.Get("ID_PART_TABLE_TYPE")
+}
+
+func (b *BlockDevice) PartitionTableUUID() string {
+	return b.Get("ID_PART_TABLE_UUID")
+}
+
+func (b *BlockDevice) PartitionUUID() string {
+	return b.Get("ID_PART_ENTRY_UUID")
+}
+
+func (b *BlockDevice) PartitionLabel() string {
+	return b.Get("ID_PART_ENTRY_NAME")
+}
+
+func (b *BlockDevice) FilesystemType() string {
+	return b.Get("ID_FS_TYPE")
+}
+
+func (b *BlockDevice) FilesystemUUID() string {
+	return b.Get("ID_FS_UUID")
+}
+
+func (b *BlockDevice) FilesystemLabel() string {
+	return b.Get("ID_FS_LABEL")
+}
+
+// Disk returns the disk of a partition
+func (b *BlockDevice) Disk() (*BlockDevice, error) {
+	if !b.IsPartition() {
+		return nil, ErrNoParentDevice
+	}
+
+	p, err := b.FindParent("block", "disk")
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockDevice{p}, nil
+}
+
+// Partitions returns the partitions of a disk, ordered by partition number
+func (b *BlockDevice) Partitions() ([]*BlockDevice, error) {
+	ds, err := b.Children(func(p *Device) FilterFn {
+		return WithFilterBlockDevtype("partition")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ps := make([]*BlockDevice, 0, len(ds))
+	for _, d := range ds {
+		ps = append(ps, &BlockDevice{d})
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		return ps[i].PartitionNumber() < ps[j].PartitionNumber()
+	})
+
+	return ps, nil
+}
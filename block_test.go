@@ -0,0 +1,52 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockDevice(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "block", "nvme0n1")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	b, err := NewBlockDevice(d)
+	assert.Nil(t, err)
+	assert.True(t, b.IsDisk())
+	assert.NotZero(t, b.Size())
+	assert.NotZero(t, b.LogicalBlockSize())
+	assert.Equal(t, "nvme", b.Transport())
+
+	spew.Dump(b.Size(), b.LogicalBlockSize(), b.PhysicalBlockSize(), b.Rotational(), b.ReadOnly(), b.Removable())
+	spew.Dump(b.Serial(), b.WWN(), b.Model(), b.PartitionTableType())
+
+	ps, err := b.Partitions()
+	assert.Nil(t, err)
+	for _, p := range ps {
+		assert.True(t, p.IsPartition())
+		spew.Dump(p.String(), p.PartitionNumber(), p.Size(), p.FilesystemType(), p.FilesystemUUID(), p.FilesystemLabel())
+
+		disk, err := p.Disk()
+		assert.Nil(t, err)
+		assert.Equal(t, d.SysPath(), disk.SysPath())
+		disk.Free()
+		p.Free()
+	}
+}
+
+func TestNewBlockDeviceNotBlock(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "pci", "0000:65:00.0")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	_, err = NewBlockDevice(d)
+	assert.Equal(t, ErrNotBlockDevice, err)
+}
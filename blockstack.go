@@ -0,0 +1,255 @@
+package goudev
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// block layer types, named like lsblk TYPE; md devices use their md/level (raid1, ...)
+const (
+	BlockLayerDisk      = "disk"
+	BlockLayerPartition = "part"
+	BlockLayerRom       = "rom"
+	BlockLayerLoop      = "loop"
+	BlockLayerCrypt     = "crypt"
+	BlockLayerLVM       = "lvm"
+	BlockLayerMultipath = "mpath"
+	BlockLayerDM        = "dm"
+	BlockLayerRaid      = "raid"
+	BlockLayerBcache    = "bcache"
+)
+
+// Name is the dm name (vg-lv) for device-mapper devices and the sysname otherwise
+func (b *BlockDevice) Name() string {
+	if v := strings.TrimSpace(b.GetAttribute("dm/name")); v != "" {
+		return v
+	}
+
+	return b.SysName()
+}
+
+// Layer identifies what kind of block device b is, see BlockLayer*
+func (b *BlockDevice) Layer() string {
+	name := b.SysName()
+
+	switch {
+	case b.IsPartition():
+		return BlockLayerPartition
+	case strings.HasPrefix(name, "dm-"):
+		// https://github.com/util-linux/util-linux/blob/master/misc-utils/lsblk.c get_type()
+		uuid := b.GetAttribute("dm/uuid")
+		switch {
+		case strings.HasPrefix(uuid, "CRYPT-"):
+			return BlockLayerCrypt
+		case strings.HasPrefix(uuid, "LVM-"):
+			return BlockLayerLVM
+		case strings.HasPrefix(uuid, "mpath-"):
+			return BlockLayerMultipath
+		case strings.HasPrefix(uuid, "part"):
+			return BlockLayerPartition
+		}
+		return BlockLayerDM
+	case strings.HasPrefix(name, "md"):
+		if level := strings.TrimSpace(b.GetAttribute("md/level")); level != "" {
+			return level
+		}
+		return BlockLayerRaid
+	case strings.HasPrefix(name, "loop"):
+		return BlockLayerLoop
+	case strings.HasPrefix(name, "bcache"):
+		return BlockLayerBcache
+	case strings.TrimSpace(b.GetAttribute("device/type")) == "5": // TYPE_ROM
+		return BlockLayerRom
+	}
+
+	return BlockLayerDisk
+}
+
+func (b *BlockDevice) stackDir(dir string) ([]*BlockDevice, error) {
+	entries, err := os.ReadDir(filepath.Join(b.SysPath(), dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	bs := make([]*BlockDevice, 0, len(entries))
+	for _, e := range entries {
+		p, err := filepath.EvalSymlinks(filepath.Join(b.SysPath(), dir, e.Name()))
+		if err != nil {
+			continue // removed meanwhile
+		}
+
+		d, err := Devices.FromSysPath(b.context(), p)
+		if err != nil {
+			continue
+		}
+		bs = append(bs, &BlockDevice{d})
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		return bs[i].SysName() < bs[j].SysName()
+	})
+
+	return bs, nil
+}
+
+// Holders returns the devices directly stacked on b (holders/)
+func (b *BlockDevice) Holders() ([]*BlockDevice, error) {
+	return b.stackDir("holders")
+}
+
+// Slaves returns the devices b is directly built from (slaves/)
+func (b *BlockDevice) Slaves() ([]*BlockDevice, error) {
+	return b.stackDir("slaves")
+}
+
+type BlockNode struct {
+	Device   *BlockDevice
+	Layer    string
+	Children []*BlockNode
+}
+
+func (n *BlockNode) Free() {
+	for _, c := range n.Children {
+		c.Free()
+	}
+	n.Device.Free()
+}
+
+// Leaves returns the nodes without children, e.g. the physical disks of a SlaveTree
+func (n *BlockNode) Leaves() []*BlockNode {
+	if len(n.Children) == 0 {
+		return []*BlockNode{n}
+	}
+
+	var ls []*BlockNode
+	for _, c := range n.Children {
+		ls = append(ls, c.Leaves()...)
+	}
+	return ls
+}
+
+// Walk calls fn for n and all descendants, depth first
+func (n *BlockNode) Walk(fn func(n *BlockNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *BlockNode) walk(fn func(n *BlockNode, depth int), depth int) {
+	fn(n, depth)
+	for _, c := range n.Children {
+		c.walk(fn, depth+1)
+	}
+}
+
+// Render writes the tree like `lsblk -s`/`lsblk` without columns
+func (n *BlockNode) Render(w io.Writer) error {
+	return n.render(w, "", "")
+}
+
+func (n *BlockNode) render(w io.Writer, prefix, childPrefix string) error {
+	if _, err := fmt.Fprintf(w, "%s%s %s\n", prefix, n.Device.Name(), n.Layer); err != nil {
+		return err
+	}
+
+	for i, c := range n.Children {
+		p, cp := "├─", "│ "
+		if i == len(n.Children)-1 {
+			p, cp = "└─", "  "
+		}
+		if err := c.render(w, childPrefix+p, childPrefix+cp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *BlockNode) String() string {
+	var sb strings.Builder
+	n.Render(&sb)
+	return sb.String()
+}
+
+// HolderTree returns everything stacked on b: partitions of a disk and holders,
+// recursively. All nodes must be released with Free.
+func (b *BlockDevice) HolderTree() (*BlockNode, error) {
+	return b.stackTree(func(b *BlockDevice) ([]*BlockDevice, error) {
+		hs, err := b.Holders()
+		if err != nil || !b.IsDisk() {
+			return hs, err
+		}
+
+		ps, err := b.Partitions()
+		if err != nil {
+			FreeBlockDevices(hs)
+			return nil, err
+		}
+		return append(ps, hs...), nil
+	}, map[string]bool{})
+}
+
+// SlaveTree returns everything b is built from down to the disks, like `lsblk -s`.
+// All nodes must be released with Free.
+func (b *BlockDevice) SlaveTree() (*BlockNode, error) {
+	return b.stackTree(func(b *BlockDevice) ([]*BlockDevice, error) {
+		ss, err := b.Slaves()
+		if err != nil || !b.IsPartition() {
+			return ss, err
+		}
+
+		disk, err := b.Disk()
+		if err != nil {
+			FreeBlockDevices(ss)
+			return nil, err
+		}
+		return append(ss, disk), nil
+	}, map[string]bool{})
+}
+
+func (b *BlockDevice) stackTree(next func(b *BlockDevice) ([]*BlockDevice, error), seen map[string]bool) (*BlockNode, error) {
+	// b is shared with the caller, the node owns its own reference
+	d, err := Devices.FromSysPath(b.context(), b.SysPath())
+	if err != nil {
+		return nil, err
+	}
+	n := &BlockNode{
+		Device: &BlockDevice{d},
+		Layer:  b.Layer(),
+	}
+	// only ancestors are tracked, a disk under two partitions shows up twice like in lsblk
+	seen[b.SysPath()] = true
+	defer delete(seen, b.SysPath())
+
+	bs, err := next(b)
+	if err != nil {
+		n.Free()
+		return nil, err
+	}
+	defer FreeBlockDevices(bs)
+
+	for _, c := range bs {
+		if seen[c.SysPath()] {
+			continue
+		}
+
+		cn, err := c.stackTree(next, seen)
+		if err != nil {
+			n.Free()
+			return nil, err
+		}
+		n.Children = append(n.Children, cn)
+	}
+
+	return n, nil
+}
+
+func FreeBlockDevices(bs []*BlockDevice) {
+	for i := range bs {
+		bs[i].Free()
+	}
+}
@@ -0,0 +1,43 @@
+package goudev
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockStack(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "block", "nvme0n1")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	b, err := NewBlockDevice(d)
+	assert.Nil(t, err)
+	assert.Equal(t, BlockLayerDisk, b.Layer())
+
+	up, err := b.HolderTree()
+	assert.Nil(t, err)
+	defer up.Free()
+	fmt.Print(up)
+
+	up.Walk(func(n *BlockNode, depth int) {
+		if depth == 0 {
+			return
+		}
+
+		down, err := n.Device.SlaveTree()
+		assert.Nil(t, err)
+		fmt.Print(down)
+
+		found := false
+		for _, l := range down.Leaves() {
+			found = found || l.Device.SysPath() == d.SysPath()
+		}
+		assert.True(t, found)
+		down.Free()
+	})
+}
@@ -0,0 +1,119 @@
+// lsblk lists block devices like util-linux lsblk, using only libudev and sysfs
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/meilihao/goudev"
+)
+
+var (
+	flagJSON  = flag.Bool("json", false, "use JSON output format, like lsblk --json --bytes")
+	flagBytes = flag.Bool("bytes", false, "print SIZE in bytes rather than in a human readable format")
+	flagFs    = flag.Bool("fs", false, "output info about filesystems")
+	flagHw    = flag.Bool("hw", false, "output info about the hardware: ROTA, TRAN, MODEL, SERIAL")
+)
+
+func humanSize(n uint64) string {
+	const units = "BKMGTPE"
+
+	f := float64(n)
+	i := 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%dB", n)
+	}
+	if f >= 10 || f == float64(int64(f)) {
+		return fmt.Sprintf("%.0f%c", f, units[i])
+	}
+	return fmt.Sprintf("%.1f%c", f, units[i])
+}
+
+func bool01(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func mountpoints(info *goudev.BlockInfo) string {
+	ms := make([]string, 0, len(info.Mountpoints))
+	for _, m := range info.Mountpoints {
+		if m != "" {
+			ms = append(ms, string(m))
+		}
+	}
+	return strings.Join(ms, ",")
+}
+
+func printInfo(w *tabwriter.Writer, info *goudev.BlockInfo, prefix, childPrefix string) {
+	size := humanSize(info.Size)
+	if *flagBytes {
+		size = fmt.Sprint(info.Size)
+	}
+
+	cols := []string{prefix + info.Name, info.MajMin, bool01(info.Rm), size, bool01(info.Ro), info.Type}
+	if *flagHw {
+		cols = append(cols, bool01(info.Rota), string(info.Tran), string(info.Model), string(info.Serial))
+	}
+	if *flagFs {
+		cols = append(cols, string(info.FsType), string(info.Label), string(info.UUID))
+	}
+	cols = append(cols, mountpoints(info))
+	fmt.Fprintln(w, strings.Join(cols, "\t"))
+
+	for i, c := range info.Children {
+		p, cp := "├─", "│ "
+		if i == len(info.Children)-1 {
+			p, cp = "└─", "  "
+		}
+		printInfo(w, c, childPrefix+p, childPrefix+cp)
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	ctx := goudev.NewContext()
+	defer ctx.Free()
+
+	infos, err := ctx.BlockInventory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lsblk:", err)
+		os.Exit(1)
+	}
+
+	if *flagJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "   ")
+		if err = enc.Encode(map[string]interface{}{"blockdevices": infos}); err != nil {
+			fmt.Fprintln(os.Stderr, "lsblk:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	header := []string{"NAME", "MAJ:MIN", "RM", "SIZE", "RO", "TYPE"}
+	if *flagHw {
+		header = append(header, "ROTA", "TRAN", "MODEL", "SERIAL")
+	}
+	if *flagFs {
+		header = append(header, "FSTYPE", "LABEL", "UUID")
+	}
+	header = append(header, "MOUNTPOINTS")
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, info := range infos {
+		printInfo(w, info, "", "")
+	}
+	w.Flush()
+}
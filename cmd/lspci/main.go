@@ -0,0 +1,118 @@
+// lspci lists PCI devices like pciutils lspci -nn or -vmmk, using libudev, sysfs and pci.ids
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/meilihao/goudev"
+)
+
+var (
+	flagVmm    = flag.Bool("vmm", false, "machine readable output, like lspci -vmmk")
+	flagNn     = flag.Bool("nn", false, "show both textual and numeric IDs")
+	flagV      = flag.Bool("v", false, "show the programming interface, like lspci -v")
+	flagDomain = flag.Bool("D", false, "always show domain numbers")
+	flagIDs    = flag.String("i", "", "use the specified ID database instead of the default pci.ids")
+)
+
+func slot(p *goudev.PciDevice) string {
+	a, err := p.Address()
+	if err != nil {
+		return p.SysName()
+	}
+	if a.Domain == 0 && !*flagDomain {
+		return a.String()[5:]
+	}
+	return a.String()
+}
+
+func printVmm(p *goudev.PciDevice, n goudev.IDNames) {
+	fmt.Printf("Slot:\t%s\n", slot(p))
+	fmt.Printf("Class:\t%s\n", n.Class)
+	fmt.Printf("Vendor:\t%s\n", n.Vendor)
+	fmt.Printf("Device:\t%s\n", n.Device)
+	if n.SubsystemVendor != "" {
+		fmt.Printf("SVendor:\t%s\n", n.SubsystemVendor)
+		fmt.Printf("SDevice:\t%s\n", n.Subsystem)
+	}
+	if rev := p.Revision(); rev != 0 {
+		fmt.Printf("Rev:\t%02x\n", rev)
+	}
+	if progIf := p.ProgIf(); progIf != 0 {
+		fmt.Printf("ProgIf:\t%02x\n", progIf)
+	}
+	if driver := p.Driver(); driver != "" {
+		fmt.Printf("Driver:\t%s\n", driver)
+	}
+	if node := p.NumaNode(); node >= 0 {
+		fmt.Printf("NUMANode:\t%d\n", node)
+	}
+	if group, err := p.IommuGroup(); err == nil {
+		fmt.Printf("IOMMUGroup:\t%d\n", group)
+	}
+	fmt.Println()
+}
+
+func printLine(p *goudev.PciDevice, n goudev.IDNames) {
+	line := slot(p) + " " + n.Class
+	if *flagNn {
+		line += fmt.Sprintf(" [%04x]", p.Class()>>8)
+	}
+	line += ": " + n.Vendor + " " + n.Device
+	if *flagNn {
+		line += fmt.Sprintf(" [%04x:%04x]", p.VendorID(), p.DeviceID())
+	}
+	if rev := p.Revision(); rev != 0 {
+		line += fmt.Sprintf(" (rev %02x)", rev)
+	}
+	if progIf := p.ProgIf(); *flagV && progIf != 0 {
+		line += fmt.Sprintf(" (prog-if %02x", progIf)
+		if n.ProgIf != "" {
+			line += " [" + n.ProgIf + "]"
+		}
+		line += ")"
+	}
+	fmt.Println(line)
+}
+
+func main() {
+	flag.Parse()
+
+	db, err := goudev.LoadPciIDs(*flagIDs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lspci: no pci.ids, showing numeric names:", err)
+		db = goudev.NewIDDatabase()
+	}
+
+	ctx := goudev.NewContext()
+	defer ctx.Free()
+
+	e := ctx.NewEnumerate()
+	defer e.Free()
+
+	if err = e.MatchSubsystem("pci"); err != nil {
+		fmt.Fprintln(os.Stderr, "lspci:", err)
+		os.Exit(1)
+	}
+	ds, err := e.Devices(nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lspci:", err)
+		os.Exit(1)
+	}
+	defer goudev.FreeDevices(ds)
+
+	for _, d := range ds {
+		p, err := goudev.NewPciDevice(d)
+		if err != nil {
+			continue
+		}
+
+		if *flagVmm {
+			printVmm(p, db.PciNames(p))
+		} else {
+			printLine(p, db.PciNames(p))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// lsusb lists USB devices like usbutils lsusb, using only libudev and sysfs
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/meilihao/goudev"
+)
+
+var (
+	flagTree = flag.Bool("t", false, "dump the physical USB device hierarchy as a tree")
+	flagJSON = flag.Bool("json", false, "use JSON output format")
+)
+
+func flatten(infos []*goudev.UsbInfo) []*goudev.UsbInfo {
+	var all []*goudev.UsbInfo
+	for _, info := range infos {
+		all = append(all, info)
+		all = append(all, flatten(info.Children)...)
+	}
+	return all
+}
+
+// printList sorts by bus and device number like lsusb
+func printList(infos []*goudev.UsbInfo) {
+	all := flatten(infos)
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Bus != all[j].Bus {
+			return all[i].Bus < all[j].Bus
+		}
+		return all[i].Device < all[j].Device
+	})
+
+	for _, info := range all {
+		fmt.Printf("Bus %03d Device %03d: ID %s:%s %s\n", info.Bus, info.Device, info.VendorID, info.ProductID,
+			strings.TrimSpace(info.Vendor+" "+info.Product))
+	}
+}
+
+func printTree(info *goudev.UsbInfo, indent string) {
+	if info.Class == "root_hub" {
+		fmt.Printf("/:  Bus %02d.Port %d: Dev %d, Class=root_hub, Driver=%s/%dp, %s\n",
+			info.Bus, info.Port, info.Device, info.Driver, info.MaxChild, goudev.FormatUsbSpeed(info.Speed))
+	} else {
+		for _, i := range info.Interfaces {
+			driver := i.Driver
+			if driver == "" {
+				driver = "[none]"
+			}
+			fmt.Printf("%s|__ Port %d: Dev %d, If %d, Class=%s, Driver=%s, %s\n",
+				indent, info.Port, info.Device, i.Number, i.ClassName, driver, goudev.FormatUsbSpeed(info.Speed))
+		}
+	}
+
+	for _, c := range info.Children {
+		printTree(c, indent+"    ")
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	ctx := goudev.NewContext()
+	defer ctx.Free()
+
+	infos, err := ctx.UsbInventory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lsusb:", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case *flagJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(infos); err != nil {
+			fmt.Fprintln(os.Stderr, "lsusb:", err)
+			os.Exit(1)
+		}
+	case *flagTree:
+		for _, info := range infos {
+			printTree(info, "    ")
+		}
+	default:
+		printList(infos)
+	}
+}
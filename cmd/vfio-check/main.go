@@ -0,0 +1,92 @@
+// vfio-check reports whether PCI devices are ready for VFIO passthrough:
+// iommu group members and their drivers, ACS along the bridges and reset methods
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/meilihao/goudev"
+)
+
+var (
+	flagJSON = flag.Bool("json", false, "use JSON output format")
+)
+
+func printReport(r *goudev.PassthroughReport) {
+	status := "ready"
+	if !r.Ready {
+		status = "NOT ready"
+	}
+	fmt.Printf("%s %s (driver %s): %s\n", r.Address, r.Class, r.Driver, status)
+
+	if r.IommuGroup >= 0 {
+		fmt.Printf("  iommu group %d:\n", r.IommuGroup)
+		for _, m := range r.Group {
+			fmt.Printf("    %s %s driver=%s vfio=%t bridge=%t\n", m.Address, m.Class, m.Driver, m.VfioBound, m.IsBridge)
+		}
+	}
+	for _, b := range r.Bridges {
+		acs := "no ACS"
+		if b.HasACS {
+			acs = fmt.Sprintf("ACS ctrl=%#04x isolated=%t", b.ACSControl, b.Isolated)
+		}
+		fmt.Printf("  bridge %s: %s\n", b.Address, acs)
+	}
+	fmt.Printf("  reset: %s\n", strings.Join(r.ResetMethods, " "))
+	for _, p := range r.Problems {
+		fmt.Printf("  problem: %s\n", p)
+	}
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-json] [pci address ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	ctx := goudev.NewContext()
+	defer ctx.Free()
+
+	var rs []*goudev.PassthroughReport
+	if flag.NArg() == 0 {
+		var err error
+		if rs, err = ctx.PassthroughReports(); err != nil {
+			fmt.Fprintln(os.Stderr, "vfio-check:", err)
+			os.Exit(1)
+		}
+	}
+	for _, addr := range flag.Args() {
+		d, err := goudev.Devices.FromName(ctx, "pci", addr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "vfio-check:", err)
+			os.Exit(1)
+		}
+
+		r, err := (&goudev.PciDevice{Device: d}).PassthroughReport()
+		d.Free()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "vfio-check:", err)
+			os.Exit(1)
+		}
+		rs = append(rs, r)
+	}
+
+	if *flagJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rs); err != nil {
+			fmt.Fprintln(os.Stderr, "vfio-check:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	for _, r := range rs {
+		printReport(r)
+	}
+}
@@ -7,7 +7,10 @@ package goudev
 // #include <libudev.h>
 // #include <stdlib.h>
 import "C"
-import "unsafe"
+import (
+	"sort"
+	"unsafe"
+)
 
 type Context struct {
 	udev *C.struct_udev
@@ -40,3 +43,28 @@ func (c *Context) NewMonitor() *Monitor {
 		udevMoniter: C.udev_monitor_new_from_netlink(c.udev, cSource),
 	}
 }
+
+func (c *Context) NewQueue() *Queue {
+	return &Queue{
+		udevQueue: C.udev_queue_new(c.udev),
+	}
+}
+
+// subsystemDevices returns the devices of subsystem that pass filter, ordered by sysname
+func (c *Context) subsystemDevices(subsystem string, filter FilterFn) ([]*Device, error) {
+	e := c.NewEnumerate()
+	defer e.Free()
+
+	if err := e.MatchSubsystem(subsystem); err != nil {
+		return nil, err
+	}
+	ds, err := e.Devices(filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		return ds[i].SysName() < ds[j].SysName()
+	})
+
+	return ds, nil
+}
@@ -0,0 +1,199 @@
+package goudev
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	cpuNameRegexp    = regexp.MustCompile(`^cpu\d+$`)
+	cacheIndexRegexp = regexp.MustCompile(`^index(\d+)$`)
+
+	ErrNotCpuDevice = errors.New("udev: device is not a cpu")
+)
+
+// CpuCache is one cpu/cache/indexN entry
+type CpuCache struct {
+	ID         int    `json:"id"`
+	Level      int    `json:"level"`
+	Type       string `json:"type"` // Data, Instruction or Unified
+	Size       uint64 `json:"size"` // bytes
+	LineSize   int    `json:"line_size"`
+	Ways       int    `json:"ways"`
+	Sets       int    `json:"sets"`
+	SharedCpus []int  `json:"shared_cpus"`
+}
+
+// parseCacheSize parses sizes like 32K or 2048K
+func parseCacheSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	mult := uint64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		mult = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		mult = 1 << 30
+	}
+	v, err := strconv.ParseUint(strings.TrimRight(s, "KMG"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return v * mult, nil
+}
+
+func readCpuCache(dir string) (*CpuCache, error) {
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	atoi := func(name string) int {
+		v, _ := strconv.Atoi(read(name))
+		return v
+	}
+
+	c := &CpuCache{
+		ID:       -1,
+		Level:    atoi("level"),
+		Type:     read("type"),
+		LineSize: atoi("coherency_line_size"),
+		Ways:     atoi("ways_of_associativity"),
+		Sets:     atoi("number_of_sets"),
+	}
+	if v, err := strconv.Atoi(read("id")); err == nil {
+		c.ID = v
+	}
+
+	var err error
+	if c.Size, err = parseCacheSize(read("size")); err != nil {
+		return nil, err
+	}
+	if c.SharedCpus, err = ParseCpuList(read("shared_cpu_list")); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// CpuDevice is a typed view of a cpu subsystem Device, e.g. cpu3
+type CpuDevice struct {
+	*Device
+}
+
+func NewCpuDevice(d *Device) (*CpuDevice, error) {
+	if d.Subsystem() != "cpu" || !cpuNameRegexp.MatchString(d.SysName()) {
+		return nil, ErrNotCpuDevice
+	}
+
+	return &CpuDevice{d}, nil
+}
+
+func (c *CpuDevice) ID() int {
+	v, _ := strconv.Atoi(c.SysNumber())
+	return v
+}
+
+// IsHotpluggable reports whether the cpu has an online attribute, cpu0 usually has none
+func (c *CpuDevice) IsHotpluggable() bool {
+	_, err := os.Stat(filepath.Join(c.SysPath(), "online"))
+	return err == nil
+}
+
+// Online is read from sysfs on every call, cpus without online attribute are always online
+func (c *CpuDevice) Online() bool {
+	if !c.IsHotpluggable() {
+		return true
+	}
+	return c.readAttribute("online") == "1"
+}
+
+// SetOnline brings the cpu up or down, the kernel sends an online/offline uevent
+func (c *CpuDevice) SetOnline(online bool) error {
+	if !c.IsHotpluggable() {
+		return ErrNotHotpluggable
+	}
+
+	v := "0"
+	if online {
+		v = "1"
+	}
+	return c.SetAttribute("online", v)
+}
+
+// Node returns the numa node, -1 if the kernel has no numa support or the cpu is offline
+func (c *CpuDevice) Node() int {
+	return nodeOf(c.Device)
+}
+
+func (c *CpuDevice) topologyInt(name string) int {
+	v, err := c.attributeInt("topology/" + name)
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+func (c *CpuDevice) PackageID() int {
+	return c.topologyInt("physical_package_id")
+}
+
+func (c *CpuDevice) DieID() int {
+	return c.topologyInt("die_id")
+}
+
+func (c *CpuDevice) CoreID() int {
+	return c.topologyInt("core_id")
+}
+
+// ThreadSiblings returns the cpus sharing the core, including this one
+func (c *CpuDevice) ThreadSiblings() ([]int, error) {
+	return ParseCpuList(c.GetAttribute("topology/thread_siblings_list"))
+}
+
+// Caches returns the cache hierarchy ordered by index, L1d/L1i first
+func (c *CpuDevice) Caches() ([]*CpuCache, error) {
+	dir := filepath.Join(c.SysPath(), "cache")
+
+	var cs []*CpuCache
+	for _, i := range numbered(dir, cacheIndexRegexp) {
+		cache, err := readCpuCache(filepath.Join(dir, "index"+strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, cache)
+	}
+
+	return cs, nil
+}
+
+// Cpus returns all cpus, online or not, ordered by id
+func (c *Context) Cpus() ([]*CpuDevice, error) {
+	ds, err := c.subsystemDevices("cpu", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]*CpuDevice, 0, len(ds))
+	for _, d := range ds {
+		if cpu, err := NewCpuDevice(d); err == nil {
+			cs = append(cs, cpu)
+			continue
+		}
+		d.Free()
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].ID() < cs[j].ID()
+	})
+
+	return cs, nil
+}
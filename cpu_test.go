@@ -0,0 +1,55 @@
+package goudev
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCpuCache(t *testing.T) {
+	dir := t.TempDir()
+	for name, v := range map[string]string{
+		"id":                    "0",
+		"level":                 "2",
+		"type":                  "Unified",
+		"size":                  "2048K",
+		"coherency_line_size":   "64",
+		"ways_of_associativity": "16",
+		"number_of_sets":        "2048",
+		"shared_cpu_list":       "0-1",
+	} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(v+"\n"), 0644))
+	}
+
+	c, err := readCpuCache(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, &CpuCache{
+		ID:         0,
+		Level:      2,
+		Type:       "Unified",
+		Size:       2 << 20,
+		LineSize:   64,
+		Ways:       16,
+		Sets:       2048,
+		SharedCpus: []int{0, 1},
+	}, c)
+}
+
+func TestCpus(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	cs, err := ctx.Cpus()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, cs)
+
+	for _, c := range cs {
+		caches, err := c.Caches()
+		assert.Nil(t, err)
+		spew.Dump(c.ID(), c.Online(), c.IsHotpluggable(), c.Node(), c.PackageID(), c.CoreID(), len(caches))
+		c.Free()
+	}
+}
@@ -6,7 +6,9 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"unsafe"
 )
@@ -164,6 +166,49 @@ func (d *Device) SetAttribute(sysattr, value string) error {
 	return nil
 }
 
+func (d *Device) attributeInt(attribute string) (int, error) {
+	v := d.GetAttribute(attribute)
+	if v == "" {
+		return 0, os.ErrNotExist
+	}
+
+	return strconv.Atoi(strings.TrimSpace(v))
+}
+
+func (d *Device) attributeUint64(attribute string) (uint64, error) {
+	v := d.GetAttribute(attribute)
+	if v == "" {
+		return 0, os.ErrNotExist
+	}
+
+	return parseUint64(v)
+}
+
+// decimal, or hex with a 0x prefix like the pci ids
+func parseUint64(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") {
+		return strconv.ParseUint(s[2:], 16, 64)
+	}
+
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// readAttribute reads sysfs directly, GetAttribute caches the first value read
+// for the lifetime of the Device
+func (d *Device) readAttribute(attribute string) string {
+	data, err := os.ReadFile(filepath.Join(d.SysPath(), attribute))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// "1"/"0" sysfs flags, missing means false
+func (d *Device) attributeBool(attribute string) bool {
+	return strings.TrimSpace(d.GetAttribute(attribute)) == "1"
+}
+
 type ListEntry struct {
 	Name  string
 	Value string
@@ -279,12 +324,31 @@ func WithFilterBlockDevtype(devtype string) FilterFn {
 	}
 }
 
+func WithFilterDevtype(devtype string) FilterFn {
+	return func(td *Device) bool {
+		return td.Get("DEVTYPE") == devtype
+	}
+}
+
+func WithFilterSubsystem(subsystem string) FilterFn {
+	return func(td *Device) bool {
+		return td.Subsystem() == subsystem
+	}
+}
+
 func WithFilterTrue(devtype string) FilterFn {
 	return func(td *Device) bool {
 		return true
 	}
 }
 
+// context returns the udev context the device belongs to, it is borrowed and must not be freed
+func (d *Device) context() *Context {
+	return &Context{
+		udev: C.udev_device_get_udev(d.udevDevice),
+	}
+}
+
 func (d *Device) Children(pfilter func(p *Device) FilterFn) ([]*Device, error) {
 	e := &Enumerate{
 		udevEnumerate: C.udev_enumerate_new(C.udev_device_get_udev(d.udevDevice)),
@@ -0,0 +1,270 @@
+package goudev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	driverPollInterval = 50 * time.Millisecond
+	// applied by Rebind when ctx has no deadline
+	driverBindTimeout = 30 * time.Second
+)
+
+var (
+	ErrNoDriver           = errors.New("NoDriver")
+	ErrDriverNotBoundTmpl = "udev: driver %s did not claim %s: %w"
+	ErrDriverNotFoundTmpl = "udev: driver %s not found on bus %s"
+)
+
+func writeSysfs(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// sysfs store handlers need the value in a single write
+	if _, err = f.Write([]byte(value)); err != nil {
+		return fmt.Errorf("udev: write %s to %s: %w", value, path, err)
+	}
+
+	return nil
+}
+
+func (d *Device) busPath() string {
+	return filepath.Join("/sys/bus", d.Subsystem())
+}
+
+// CurrentDriver reads the driver link from sysfs, unlike Driver() which
+// returns the value cached when the Device was created
+func (d *Device) CurrentDriver() string {
+	p, err := os.Readlink(filepath.Join(d.SysPath(), "driver"))
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(p)
+}
+
+// DriverOverride is read from sysfs on every call, so it reflects SetDriverOverride
+func (d *Device) DriverOverride() string {
+	v := d.readAttribute("driver_override")
+	if v == "(null)" {
+		return ""
+	}
+
+	return v
+}
+
+// SetDriverOverride limits matching to driver, "" clears the override
+func (d *Device) SetDriverOverride(driver string) error {
+	if driver == "" {
+		driver = "\n"
+	}
+
+	return d.SetAttribute("driver_override", driver)
+}
+
+func (d *Device) UnbindDriver() error {
+	if d.CurrentDriver() == "" {
+		return ErrNoDriver
+	}
+
+	return writeSysfs(filepath.Join(d.SysPath(), "driver", "unbind"), d.SysName())
+}
+
+// HasDriver reports whether driver is registered on the bus of the device, i.e. loaded
+func (d *Device) HasDriver(driver string) bool {
+	_, err := os.Stat(filepath.Join(d.busPath(), "drivers", driver))
+	return err == nil
+}
+
+func (d *Device) BindDriver(driver string) error {
+	if !d.HasDriver(driver) {
+		return fmt.Errorf(ErrDriverNotFoundTmpl, driver, d.Subsystem())
+	}
+
+	return writeSysfs(filepath.Join(d.busPath(), "drivers", driver, "bind"), d.SysName())
+}
+
+// ProbeDriver asks the bus to find a driver, honouring driver_override
+func (d *Device) ProbeDriver() error {
+	return writeSysfs(filepath.Join(d.busPath(), "drivers_probe"), d.SysName())
+}
+
+// parseKernelRelease returns major and minor of a release like 6.8.0-45-generic
+func parseKernelRelease(release string) (major, minor int, err error) {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("udev: bad kernel release %q", release)
+	}
+	if major, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, err
+	}
+	// 5.10-rc1
+	n := strings.IndexFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })
+	if n < 0 {
+		n = len(parts[1])
+	}
+	minor, err = strconv.Atoi(parts[1][:n])
+	return major, minor, err
+}
+
+// bind/unbind uevents exist since 4.14
+func kernelHasBindUevents() bool {
+	var u unix.Utsname
+	if unix.Uname(&u) != nil {
+		return true
+	}
+
+	major, minor, err := parseKernelRelease(unix.ByteSliceToString(u.Release[:]))
+	if err != nil {
+		return true
+	}
+	return major > 4 || major == 4 && minor >= 14
+}
+
+// driverQuery matches the bind uevent of d to driver, or its unbind uevent for ""
+func (d *Device) driverQuery(driver string) *DeviceQuery {
+	q := &DeviceQuery{
+		Subsystem:  d.Subsystem(),
+		SysName:    d.SysName(),
+		Properties: map[string]string{"ACTION": "unbind"},
+	}
+	if driver != "" {
+		q.Properties["ACTION"] = "bind"
+		q.Properties["DRIVER"] = driver
+	}
+
+	return q
+}
+
+// waitDriver waits for the bind/unbind uevent moving d to driver ("" for unbound).
+// The driver link is polled only on kernels without these uevents (< 4.14) or when
+// the monitor is gone.
+func (d *Device) waitDriver(ctx context.Context, ch <-chan *Device, driver string) error {
+	q := d.driverQuery(driver)
+
+	var ticker *time.Ticker
+	var poll <-chan time.Time
+	startPolling := func() {
+		ticker = time.NewTicker(driverPollInterval)
+		poll = ticker.C
+	}
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	if !kernelHasBindUevents() {
+		startPolling()
+	}
+
+	for d.CurrentDriver() != driver {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-poll:
+		case e, ok := <-ch:
+			if !ok {
+				ch = nil
+				startPolling()
+				continue
+			}
+			matched := q.Match(e)
+			e.Free()
+			if matched {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// Rebind moves the device to driver: unbind the current driver, set driver_override,
+// probe and wait for driver to claim the device. On failure the previous driver and
+// driver_override are restored. Without a ctx deadline driverBindTimeout applies.
+func (d *Device) Rebind(ctx context.Context, driver string) (err error) {
+	oldDriver := d.CurrentDriver()
+	if oldDriver == driver {
+		return nil
+	}
+	// checked up front, a missing driver would never claim the device
+	if !d.HasDriver(driver) {
+		return fmt.Errorf(ErrDriverNotFoundTmpl, driver, d.Subsystem())
+	}
+	oldOverride := d.DriverOverride()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, driverBindTimeout)
+		defer cancel()
+	}
+
+	mctx, cancel := context.WithCancel(ctx)
+	m := d.context().NewMonitor()
+	defer m.Free()
+	if err = m.FilterBy(d.Subsystem()); err != nil {
+		cancel()
+		return err
+	}
+	ch, err := m.DeviceChan(mctx, waitEpollTimeout)
+	if err != nil {
+		cancel()
+		return err
+	}
+	defer drainDeviceChan(ch)
+	defer cancel()
+
+	if oldDriver != "" {
+		if err = d.UnbindDriver(); err != nil {
+			return err
+		}
+		if err = d.waitDriver(ctx, ch, ""); err != nil {
+			d.rollbackDriver(oldDriver, oldOverride)
+			return err
+		}
+	}
+
+	defer func() {
+		if err != nil {
+			d.rollbackDriver(oldDriver, oldOverride)
+			err = fmt.Errorf(ErrDriverNotBoundTmpl, driver, d.SysName(), err)
+		}
+	}()
+
+	// buses without driver_override fall back to a direct bind
+	if d.SetDriverOverride(driver) == nil {
+		err = d.ProbeDriver()
+	} else {
+		err = d.BindDriver(driver)
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.waitDriver(ctx, ch, driver)
+}
+
+func (d *Device) rollbackDriver(driver, override string) {
+	if d.CurrentDriver() != "" {
+		d.UnbindDriver()
+	}
+	d.SetDriverOverride(override)
+	if driver == "" {
+		return
+	}
+	if d.BindDriver(driver) != nil {
+		d.ProbeDriver()
+	}
+}
@@ -0,0 +1,72 @@
+package goudev
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceCurrentDriver(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "pci", "0000:65:00.0")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	assert.Equal(t, d.Driver(), d.CurrentDriver())
+	spew.Dump(d.CurrentDriver())
+	spew.Dump(d.DriverOverride())
+}
+
+func TestParseKernelRelease(t *testing.T) {
+	for release, want := range map[string][2]int{
+		"6.8.0-45-generic":       {6, 8},
+		"4.14.336":               {4, 14},
+		"6.18.44-fc-v139":        {6, 18},
+		"5.10-rc1":               {5, 10},
+		"3.10.0-1160.el7.x86_64": {3, 10},
+	} {
+		major, minor, err := parseKernelRelease(release)
+		assert.Nil(t, err)
+		assert.Equal(t, want, [2]int{major, minor}, release)
+	}
+
+	_, _, err := parseKernelRelease("6")
+	assert.NotNil(t, err)
+}
+
+func TestRebindMissingDriver(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromSysPath(ctx, "/sys/devices/virtual/mem/null")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	assert.False(t, d.HasDriver("no-such-driver"))
+
+	// returns at once instead of waiting for a driver that never binds
+	err = d.Rebind(context.Background(), "no-such-driver")
+	assert.NotNil(t, err)
+	assert.Equal(t, "", d.CurrentDriver())
+}
+
+func TestDriverQuery(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromSysPath(ctx, "/sys/devices/virtual/mem/null")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	q := d.driverQuery("vfio-pci")
+	assert.Equal(t, map[string]string{"ACTION": "bind", "DRIVER": "vfio-pci"}, q.Properties)
+	assert.Equal(t, "null", q.SysName)
+
+	// the enumerated device carries no ACTION, so it never counts as the event
+	assert.False(t, q.Match(d))
+	assert.False(t, d.driverQuery("").Match(d))
+}
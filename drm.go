@@ -0,0 +1,404 @@
+package goudev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// connector status values
+const (
+	DrmConnected    = "connected"
+	DrmDisconnected = "disconnected"
+	DrmUnknown      = "unknown"
+)
+
+const (
+	edidBlockSize = 128
+)
+
+var (
+	edidHeader         = []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}
+	drmCardNameRegexp  = regexp.MustCompile(`^card\d+$`)
+	ErrNotDrmCard      = errors.New("udev: device is not a drm card")
+	ErrNotDrmConnector = errors.New("udev: device is not a drm connector")
+	ErrNoEDID          = errors.New("udev: connector has no edid")
+	ErrBadEDID         = errors.New("udev: bad edid")
+)
+
+type EdidMode struct {
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Refresh   float64 `json:"refresh"` // Hz
+	Preferred bool    `json:"preferred,omitempty"`
+}
+
+func (m EdidMode) String() string {
+	return fmt.Sprintf("%dx%d@%.2f", m.Width, m.Height, m.Refresh)
+}
+
+// EDID is the base block of an EDID 1.x, extension blocks are ignored
+type EDID struct {
+	Manufacturer string `json:"manufacturer"` // PNP id, e.g. DEL
+	ProductCode  uint16 `json:"product_code"`
+	// the numeric serial, many monitors use SerialString instead
+	Serial       uint32     `json:"serial"`
+	SerialString string     `json:"serial_string,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	Week         int        `json:"week"`
+	Year         int        `json:"year"`
+	Version      string     `json:"version"`
+	WidthCm      int        `json:"width_cm"`
+	HeightCm     int        `json:"height_cm"`
+	Extensions   int        `json:"extensions"`
+	Modes        []EdidMode `json:"modes"`
+}
+
+// Preferred returns the preferred mode, the first detailed timing
+func (e *EDID) Preferred() (EdidMode, bool) {
+	for _, m := range e.Modes {
+		if m.Preferred {
+			return m, true
+		}
+	}
+	return EdidMode{}, false
+}
+
+func edidString(b []byte) string {
+	if i := strings.IndexByte(string(b), '\n'); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func edidDetailedTiming(b []byte) (EdidMode, bool) {
+	clock := int(b[0]) | int(b[1])<<8 // 10 kHz
+	hActive := int(b[2]) | int(b[4]>>4)<<8
+	hBlank := int(b[3]) | int(b[4]&0xf)<<8
+	vActive := int(b[5]) | int(b[7]>>4)<<8
+	vBlank := int(b[6]) | int(b[7]&0xf)<<8
+
+	total := (hActive + hBlank) * (vActive + vBlank)
+	if clock == 0 || total == 0 {
+		return EdidMode{}, false
+	}
+
+	refresh := float64(clock) * 10000 / float64(total)
+	return EdidMode{Width: hActive, Height: vActive, Refresh: float64(int(refresh*100+0.5)) / 100}, true
+}
+
+// ParseEDID parses the base EDID block, see VESA E-EDID 1.4 section 3
+func ParseEDID(data []byte) (*EDID, error) {
+	if len(data) < edidBlockSize || string(data[:8]) != string(edidHeader) {
+		return nil, ErrBadEDID
+	}
+
+	var sum byte
+	for _, b := range data[:edidBlockSize] {
+		sum += b
+	}
+	if sum != 0 {
+		return nil, ErrBadEDID
+	}
+
+	mfg := uint16(data[8])<<8 | uint16(data[9])
+	e := &EDID{
+		Manufacturer: string([]byte{
+			byte('A' - 1 + (mfg>>10)&0x1f),
+			byte('A' - 1 + (mfg>>5)&0x1f),
+			byte('A' - 1 + mfg&0x1f),
+		}),
+		ProductCode: uint16(data[10]) | uint16(data[11])<<8,
+		Serial:      uint32(data[12]) | uint32(data[13])<<8 | uint32(data[14])<<16 | uint32(data[15])<<24,
+		Week:        int(data[16]),
+		Year:        int(data[17]) + 1990,
+		Version:     fmt.Sprintf("%d.%d", data[18], data[19]),
+		WidthCm:     int(data[21]),
+		HeightCm:    int(data[22]),
+		Extensions:  int(data[126]),
+	}
+
+	// 18 byte descriptors: detailed timings or display descriptors
+	for i := 54; i < 126; i += 18 {
+		b := data[i : i+18]
+		if b[0] != 0 || b[1] != 0 {
+			if m, ok := edidDetailedTiming(b); ok {
+				m.Preferred = len(e.Modes) == 0
+				e.Modes = append(e.Modes, m)
+			}
+			continue
+		}
+
+		switch b[3] {
+		case 0xfc:
+			e.Name = edidString(b[5:])
+		case 0xff:
+			e.SerialString = edidString(b[5:])
+		}
+	}
+
+	// standard timings, 16:10 aspect uses 0 since EDID 1.3
+	for i := 38; i < 54; i += 2 {
+		if data[i] == 0x01 && data[i+1] == 0x01 || data[i] == 0 {
+			continue
+		}
+
+		w := (int(data[i]) + 31) * 8
+		var h int
+		switch data[i+1] >> 6 {
+		case 0:
+			h = w * 10 / 16
+		case 1:
+			h = w * 3 / 4
+		case 2:
+			h = w * 4 / 5
+		case 3:
+			h = w * 9 / 16
+		}
+		e.Modes = append(e.Modes, EdidMode{Width: w, Height: h, Refresh: float64(data[i+1]&0x3f) + 60})
+	}
+
+	return e, nil
+}
+
+// DrmCard is a typed view of a drm cardN Device
+type DrmCard struct {
+	*Device
+}
+
+func NewDrmCard(d *Device) (*DrmCard, error) {
+	if d.Subsystem() != "drm" || !drmCardNameRegexp.MatchString(d.SysName()) {
+		return nil, ErrNotDrmCard
+	}
+
+	return &DrmCard{d}, nil
+}
+
+// PciDevice returns the GPU, ErrNoParentDevice for non PCI cards like simpledrm
+func (c *DrmCard) PciDevice() (*PciDevice, error) {
+	p, err := c.FindParent("pci")
+	if err != nil {
+		return nil, err
+	}
+
+	return &PciDevice{p}, nil
+}
+
+// IsBootVGA reports whether the firmware used this card for the boot console
+func (c *DrmCard) IsBootVGA() bool {
+	p, err := c.PciDevice()
+	if err != nil {
+		return false
+	}
+	defer p.Free()
+
+	return p.attributeBool("boot_vga")
+}
+
+// Driver returns the kernel driver of the GPU, e.g. i915, amdgpu or nouveau
+func (c *DrmCard) Driver() string {
+	p, err := c.Parent()
+	if err != nil {
+		return ""
+	}
+	defer p.Free()
+
+	return p.CurrentDriver()
+}
+
+func (c *DrmCard) Connectors() ([]*DrmConnector, error) {
+	ds, err := c.Children(func(p *Device) FilterFn {
+		prefix := p.SysName() + "-"
+		return func(td *Device) bool {
+			return td.Subsystem() == "drm" && strings.HasPrefix(td.SysName(), prefix)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]*DrmConnector, 0, len(ds))
+	for _, d := range ds {
+		cs = append(cs, &DrmConnector{d})
+	}
+
+	return cs, nil
+}
+
+// DrmConnector is a typed view of a drm connector Device, e.g. card0-HDMI-A-1
+type DrmConnector struct {
+	*Device
+}
+
+func NewDrmConnector(d *Device) (*DrmConnector, error) {
+	if d.Subsystem() != "drm" || !strings.HasPrefix(d.SysName(), "card") || !strings.Contains(d.SysName(), "-") {
+		return nil, ErrNotDrmConnector
+	}
+
+	return &DrmConnector{d}, nil
+}
+
+// Name returns the connector name without the card, e.g. HDMI-A-1
+func (c *DrmConnector) Name() string {
+	_, name, _ := strings.Cut(c.SysName(), "-")
+	return name
+}
+
+func (c *DrmConnector) Card() string {
+	card, _, _ := strings.Cut(c.SysName(), "-")
+	return card
+}
+
+func (c *DrmConnector) ConnectorID() int {
+	v, _ := c.attributeInt("connector_id")
+	return v
+}
+
+// Status is read from sysfs on every call since it changes on hotplug
+func (c *DrmConnector) Status() string {
+	return c.readAttribute("status")
+}
+
+func (c *DrmConnector) Enabled() bool {
+	return c.readAttribute("enabled") == "enabled"
+}
+
+// DPMS returns On, Standby, Suspend or Off
+func (c *DrmConnector) DPMS() string {
+	return c.readAttribute("dpms")
+}
+
+// Modes returns the mode names the kernel probed, e.g. 1920x1080
+func (c *DrmConnector) Modes() []string {
+	return strings.Fields(c.readAttribute("modes"))
+}
+
+func (c *DrmConnector) EDID() (*EDID, error) {
+	data, err := os.ReadFile(filepath.Join(c.SysPath(), "edid"))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrNoEDID
+	}
+
+	return ParseEDID(data)
+}
+
+// DrmCards returns the cardN devices ordered by name, render nodes are skipped
+func (c *Context) DrmCards() ([]*DrmCard, error) {
+	ds, err := c.subsystemDevices("drm", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]*DrmCard, 0, len(ds))
+	for _, d := range ds {
+		if card, err := NewDrmCard(d); err == nil {
+			cs = append(cs, card)
+			continue
+		}
+		d.Free()
+	}
+
+	return cs, nil
+}
+
+type DrmConnectorEvent struct {
+	Connector string // e.g. card0-HDMI-A-1
+	OldStatus string
+	Status    string
+}
+
+func (c *Context) drmConnectorStatus() (map[string]string, error) {
+	ds, err := c.subsystemDevices("drm", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer FreeDevices(ds)
+
+	st := map[string]string{}
+	for _, d := range ds {
+		if con, err := NewDrmConnector(d); err == nil {
+			st[d.SysName()] = con.Status()
+		}
+	}
+
+	return st, nil
+}
+
+// DrmConnectorEvents reports connector status changes until ctx is done. The kernel
+// sends a change uevent with HOTPLUG=1 on the card without the status, so the
+// connectors are re-read and compared with the previous state.
+func (c *Context) DrmConnectorEvents(ctx context.Context) (<-chan *DrmConnectorEvent, error) {
+	m := c.NewMonitor()
+	if err := m.FilterBy("drm"); err != nil {
+		m.Free()
+		return nil, err
+	}
+
+	mctx, cancel := context.WithCancel(ctx)
+	ch, err := m.DeviceChan(mctx, waitEpollTimeout)
+	if err != nil {
+		cancel()
+		m.Free()
+		return nil, err
+	}
+
+	// after the monitor is listening, see WaitForDevice
+	last, err := c.drmConnectorStatus()
+	if err != nil {
+		cancel()
+		drainDeviceChan(ch)
+		m.Free()
+		return nil, err
+	}
+
+	out := make(chan *DrmConnectorEvent)
+	go func() {
+		defer m.Free()
+		defer close(out)
+		defer drainDeviceChan(ch)
+		defer cancel()
+
+		for d := range ch {
+			hotplug := d.Get("HOTPLUG") == "1" || d.Action() == "add" || d.Action() == "remove"
+			d.Free()
+			if !hotplug {
+				continue
+			}
+
+			cur, err := c.drmConnectorStatus()
+			if err != nil {
+				continue
+			}
+
+			var evs []*DrmConnectorEvent
+			for name, st := range cur {
+				if last[name] != st {
+					evs = append(evs, &DrmConnectorEvent{Connector: name, OldStatus: last[name], Status: st})
+				}
+			}
+			for name, st := range last {
+				if _, ok := cur[name]; !ok {
+					evs = append(evs, &DrmConnectorEvent{Connector: name, OldStatus: st})
+				}
+			}
+			last = cur
+
+			for _, ev := range evs {
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
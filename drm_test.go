@@ -0,0 +1,70 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+// DEL a0ec "DELL U2720Q", 1920x1080@60 detailed timing, 1280x960@60 standard timing
+var testEDID = []byte{
+	0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x10, 0xac, 0xec, 0xa0, 0x30, 0x4d, 0x4a, 0x4c,
+	0x0c, 0x1e, 0x01, 0x04, 0x00, 0x35, 0x1e, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x81, 0x40, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+	0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x3a, 0x80, 0x18, 0x71, 0x38, 0x2d, 0x40, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xfc, 0x00, 0x44, 0x45, 0x4c,
+	0x4c, 0x20, 0x55, 0x32, 0x37, 0x32, 0x30, 0x51, 0x0a, 0x20, 0x00, 0x00, 0x00, 0xff, 0x00, 0x41,
+	0x42, 0x43, 0x31, 0x32, 0x33, 0x34, 0x0a, 0x20, 0x20, 0x20, 0x20, 0x20, 0x00, 0x00, 0x00, 0x10,
+	0x00, 0x0a, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x00, 0xc5,
+}
+
+func TestParseEDID(t *testing.T) {
+	e, err := ParseEDID(testEDID)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "DEL", e.Manufacturer)
+	assert.Equal(t, uint16(0xa0ec), e.ProductCode)
+	assert.Equal(t, "DELL U2720Q", e.Name)
+	assert.Equal(t, "ABC1234", e.SerialString)
+	assert.Equal(t, 2020, e.Year)
+	assert.Equal(t, "1.4", e.Version)
+	assert.Equal(t, []EdidMode{
+		{Width: 1920, Height: 1080, Refresh: 60, Preferred: true},
+		{Width: 1280, Height: 960, Refresh: 60},
+	}, e.Modes)
+
+	m, ok := e.Preferred()
+	assert.True(t, ok)
+	assert.Equal(t, "1920x1080@60.00", m.String())
+
+	bad := append([]byte{}, testEDID...)
+	bad[20] = 0xff
+	_, err = ParseEDID(bad)
+	assert.Equal(t, ErrBadEDID, err)
+}
+
+func TestDrmCards(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	cs, err := ctx.DrmCards()
+	assert.Nil(t, err)
+
+	for _, c := range cs {
+		spew.Dump(c.SysName(), c.Driver(), c.IsBootVGA())
+
+		cons, err := c.Connectors()
+		assert.Nil(t, err)
+		for _, con := range cons {
+			spew.Dump(con.Name(), con.Status(), con.Enabled(), con.DPMS())
+			if con.Status() == DrmConnected {
+				e, err := con.EDID()
+				assert.Nil(t, err)
+				spew.Dump(e)
+			}
+			con.Free()
+		}
+		c.Free()
+	}
+}
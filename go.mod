@@ -4,7 +4,9 @@ go 1.22.4
 
 require (
 	github.com/davecgh/go-spew v1.1.1
+	github.com/meilihao/golib/v2 v2.0.0-20231019104548-a76a1b694989
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/sys v0.22.0
 )
 
 require (
@@ -15,7 +17,6 @@ require (
 	github.com/grpc-ecosystem/go-grpc-middleware v1.3.0 // indirect
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.16.0 // indirect
 	github.com/json-iterator/go v1.1.11 // indirect
-	github.com/meilihao/golib/v2 v2.0.0-20231019104548-a76a1b694989 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
@@ -34,7 +35,6 @@ require (
 	go.uber.org/multierr v1.6.0 // indirect
 	go.uber.org/zap v1.19.0 // indirect
 	golang.org/x/net v0.12.0 // indirect
-	golang.org/x/sys v0.22.0 // indirect
 	golang.org/x/text v0.11.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20230711160842-782d3b101e98 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
@@ -0,0 +1,428 @@
+package goudev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HID 1.11 6.2.2 item types and tags
+const (
+	hidItemMain   = 0
+	hidItemGlobal = 1
+	hidItemLocal  = 2
+	hidItemLong   = 0xfe
+
+	hidMainInput         = 0x8
+	hidMainOutput        = 0x9
+	hidMainCollection    = 0xa
+	hidMainFeature       = 0xb
+	hidMainEndCollection = 0xc
+
+	hidGlobalUsagePage   = 0x0
+	hidGlobalLogicalMin  = 0x1
+	hidGlobalLogicalMax  = 0x2
+	hidGlobalReportSize  = 0x7
+	hidGlobalReportID    = 0x8
+	hidGlobalReportCount = 0x9
+	hidGlobalPush        = 0xa
+	hidGlobalPop         = 0xb
+
+	hidLocalUsage    = 0x0
+	hidLocalUsageMin = 0x1
+	hidLocalUsageMax = 0x2
+)
+
+// main item data bits
+const (
+	HidFieldConstant = 0x01
+	HidFieldVariable = 0x02
+	HidFieldRelative = 0x04
+)
+
+type HidReportKind int
+
+const (
+	HidReportInput HidReportKind = iota
+	HidReportOutput
+	HidReportFeature
+)
+
+func (k HidReportKind) String() string {
+	switch k {
+	case HidReportInput:
+		return "input"
+	case HidReportOutput:
+		return "output"
+	case HidReportFeature:
+		return "feature"
+	}
+	return "unknown"
+}
+
+// https://usb.org/document-library/hid-usage-tables-15
+var hidUsagePageNames = map[uint16]string{
+	0x01:   "Generic Desktop",
+	0x02:   "Simulation Controls",
+	0x03:   "VR Controls",
+	0x05:   "Game Controls",
+	0x06:   "Generic Device Controls",
+	0x07:   "Keyboard/Keypad",
+	0x08:   "LED",
+	0x09:   "Button",
+	0x0b:   "Telephony",
+	0x0c:   "Consumer",
+	0x0d:   "Digitizers",
+	0x0e:   "Haptics",
+	0x0f:   "Physical Input Device",
+	0x14:   "Auxiliary Display",
+	0x20:   "Sensors",
+	0x40:   "Medical Instrument",
+	0x59:   "Lighting And Illumination",
+	0x84:   "Power",
+	0x85:   "Battery System",
+	0x8c:   "Barcode Scanner",
+	0x8d:   "Scales",
+	0x8e:   "Magnetic Stripe Reader",
+	0x92:   "Camera Control",
+	0xf1d0: "FIDO Alliance",
+}
+
+func HidUsagePageName(page uint16) string {
+	if s, ok := hidUsagePageNames[page]; ok {
+		return s
+	}
+	if page >= 0xff00 {
+		return fmt.Sprintf("Vendor Defined %04x", page)
+	}
+
+	return fmt.Sprintf("Reserved %04x", page)
+}
+
+// HidField is one Input/Output/Feature main item. Usages are extended usages, page in
+// the upper 16 bits; a range is given by UsageMin/UsageMax.
+type HidField struct {
+	UsagePage   uint16
+	Usages      []uint32
+	UsageMin    uint32
+	UsageMax    uint32
+	LogicalMin  int32
+	LogicalMax  int32
+	ReportSize  int // bits per value
+	ReportCount int
+	Flags       uint32
+}
+
+func (f *HidField) Bits() int {
+	return f.ReportSize * f.ReportCount
+}
+
+func (f *HidField) IsConstant() bool {
+	return f.Flags&HidFieldConstant != 0
+}
+
+type HidReport struct {
+	ID     uint8 // 0 if the device does not use report ids
+	Kind   HidReportKind
+	Fields []*HidField
+}
+
+func (r *HidReport) Bits() int {
+	n := 0
+	for _, f := range r.Fields {
+		n += f.Bits()
+	}
+	return n
+}
+
+// Size in bytes including the report id prefix
+func (r *HidReport) Size() int {
+	n := (r.Bits() + 7) / 8
+	if r.ID != 0 {
+		n++
+	}
+	return n
+}
+
+// HidCollection is a top level (application) collection
+type HidCollection struct {
+	UsagePage uint16
+	Usage     uint16
+}
+
+type HidReportDescriptor struct {
+	Collections []HidCollection
+	Reports     []*HidReport
+}
+
+// Report returns the report with id and kind, nil if there is none
+func (d *HidReportDescriptor) Report(id uint8, kind HidReportKind) *HidReport {
+	for _, r := range d.Reports {
+		if r.ID == id && r.Kind == kind {
+			return r
+		}
+	}
+	return nil
+}
+
+type hidGlobals struct {
+	usagePage   uint16
+	logicalMin  int32
+	logicalMax  int32
+	reportSize  int
+	reportCount int
+	reportID    uint8
+}
+
+func hidItemValue(data []byte) (unsigned uint32, signed int32) {
+	switch len(data) {
+	case 1:
+		return uint32(data[0]), int32(int8(data[0]))
+	case 2:
+		v := uint16(data[0]) | uint16(data[1])<<8
+		return uint32(v), int32(int16(v))
+	case 4:
+		v := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
+		return v, int32(v)
+	}
+	return 0, 0
+}
+
+// ParseHidReportDescriptor parses a binary HID report descriptor into its reports
+func ParseHidReportDescriptor(data []byte) (*HidReportDescriptor, error) {
+	d := &HidReportDescriptor{}
+
+	var g hidGlobals
+	var stack []hidGlobals
+	var usages []uint32
+	var usageMin, usageMax uint32
+	depth := 0
+	reports := map[[2]int]*HidReport{}
+
+	for off := 0; off < len(data); {
+		prefix := data[off]
+		if prefix == hidItemLong {
+			if off+2 >= len(data) || off+3+int(data[off+1]) > len(data) {
+				return nil, fmt.Errorf("udev: truncated hid long item at %d", off)
+			}
+			off += 3 + int(data[off+1])
+			continue
+		}
+
+		size := int(prefix & 0x3)
+		if size == 3 {
+			size = 4
+		}
+		if off+1+size > len(data) {
+			return nil, fmt.Errorf("udev: truncated hid item at %d", off)
+		}
+		typ, tag := (prefix>>2)&0x3, prefix>>4
+		u, s := hidItemValue(data[off+1 : off+1+size])
+		off += 1 + size
+
+		// extended usages carry their page, short ones use the current usage page
+		usage := func() uint32 {
+			if size == 4 {
+				return u
+			}
+			return uint32(g.usagePage)<<16 | u
+		}
+
+		switch typ {
+		case hidItemGlobal:
+			switch tag {
+			case hidGlobalUsagePage:
+				g.usagePage = uint16(u)
+			case hidGlobalLogicalMin:
+				g.logicalMin = s
+			case hidGlobalLogicalMax:
+				// like hid-core, a non-negative minimum makes the maximum unsigned: 0..255 is 15 00 25 ff
+				if g.logicalMin >= 0 {
+					g.logicalMax = int32(u)
+				} else {
+					g.logicalMax = s
+				}
+			case hidGlobalReportSize:
+				g.reportSize = int(u)
+			case hidGlobalReportCount:
+				g.reportCount = int(u)
+			case hidGlobalReportID:
+				g.reportID = uint8(u)
+			case hidGlobalPush:
+				stack = append(stack, g)
+			case hidGlobalPop:
+				if len(stack) == 0 {
+					return nil, errors.New("udev: hid pop without push")
+				}
+				g, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case hidItemLocal:
+			switch tag {
+			case hidLocalUsage:
+				usages = append(usages, usage())
+			case hidLocalUsageMin:
+				usageMin = usage()
+			case hidLocalUsageMax:
+				usageMax = usage()
+			}
+		case hidItemMain:
+			switch tag {
+			case hidMainCollection:
+				if depth == 0 && len(usages) > 0 {
+					d.Collections = append(d.Collections, HidCollection{
+						UsagePage: uint16(usages[0] >> 16),
+						Usage:     uint16(usages[0]),
+					})
+				}
+				depth++
+			case hidMainEndCollection:
+				if depth == 0 {
+					return nil, fmt.Errorf("udev: hid end collection without collection at %d", off-1-size)
+				}
+				depth--
+			case hidMainInput, hidMainOutput, hidMainFeature:
+				kind := map[uint8]HidReportKind{hidMainInput: HidReportInput, hidMainOutput: HidReportOutput, hidMainFeature: HidReportFeature}[tag]
+				key := [2]int{int(g.reportID), int(kind)}
+				r, ok := reports[key]
+				if !ok {
+					r = &HidReport{ID: g.reportID, Kind: kind}
+					reports[key] = r
+					d.Reports = append(d.Reports, r)
+				}
+				r.Fields = append(r.Fields, &HidField{
+					UsagePage:   g.usagePage,
+					Usages:      usages,
+					UsageMin:    usageMin,
+					UsageMax:    usageMax,
+					LogicalMin:  g.logicalMin,
+					LogicalMax:  g.logicalMax,
+					ReportSize:  g.reportSize,
+					ReportCount: g.reportCount,
+					Flags:       u,
+				})
+			}
+			// local items only apply to the next main item
+			usages, usageMin, usageMax = nil, 0, 0
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("udev: %d unclosed hid collections", depth)
+	}
+
+	return d, nil
+}
+
+// HidID is the HID_ID property: bus, vendor and product
+type HidID struct {
+	Bus     uint16
+	Vendor  uint32
+	Product uint32
+}
+
+func ParseHidID(s string) (HidID, error) {
+	// 0003:0000046D:0000C52B
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return HidID{}, fmt.Errorf("udev: bad HID_ID %q", s)
+	}
+
+	var vs [3]uint64
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 16, 32)
+		if err != nil {
+			return HidID{}, fmt.Errorf("udev: bad HID_ID %q", s)
+		}
+		vs[i] = v
+	}
+
+	return HidID{uint16(vs[0]), uint32(vs[1]), uint32(vs[2])}, nil
+}
+
+var (
+	ErrNotHidrawDevice = errors.New("udev: device is not in the hidraw subsystem")
+)
+
+// HidrawDevice is a typed view of a hidraw subsystem Device
+type HidrawDevice struct {
+	*Device
+}
+
+func NewHidrawDevice(d *Device) (*HidrawDevice, error) {
+	if d.Subsystem() != "hidraw" {
+		return nil, ErrNotHidrawDevice
+	}
+
+	return &HidrawDevice{d}, nil
+}
+
+// HidDevice returns the owning device of the hid bus, holding HID_* and report_descriptor
+func (h *HidrawDevice) HidDevice() (*Device, error) {
+	return h.FindParent("hid")
+}
+
+func (h *HidrawDevice) hidProperty(property string) string {
+	p, err := h.HidDevice()
+	if err != nil {
+		return ""
+	}
+	defer p.Free()
+
+	return p.Get(property)
+}
+
+func (h *HidrawDevice) ID() (HidID, error) {
+	return ParseHidID(h.hidProperty("HID_ID"))
+}
+
+func (h *HidrawDevice) Name() string {
+	return h.hidProperty("HID_NAME")
+}
+
+// Interface returns the USB interface number, or N of HID_PHYS "usb-0000:00:14.0-1/inputN"
+func (h *HidrawDevice) Interface() (int, error) {
+	if i, err := UsbInterfaceOf(h.Device); err == nil {
+		defer i.Free()
+		return int(i.InterfaceNumber()), nil
+	}
+
+	phys := h.hidProperty("HID_PHYS")
+	if i := strings.LastIndex(phys, "/input"); i >= 0 {
+		return strconv.Atoi(phys[i+len("/input"):])
+	}
+
+	return 0, ErrNoParentDevice
+}
+
+// ReportDescriptor reads the binary report_descriptor of the hid parent
+func (h *HidrawDevice) ReportDescriptor() (*HidReportDescriptor, error) {
+	p, err := h.HidDevice()
+	if err != nil {
+		return nil, err
+	}
+	defer p.Free()
+
+	data, err := os.ReadFile(filepath.Join(p.SysPath(), "report_descriptor"))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseHidReportDescriptor(data)
+}
+
+// HidrawDevices returns all hidraw devices ordered by name
+func (c *Context) HidrawDevices() ([]*HidrawDevice, error) {
+	ds, err := c.subsystemDevices("hidraw", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	hs := make([]*HidrawDevice, 0, len(ds))
+	for _, d := range ds {
+		hs = append(hs, &HidrawDevice{d})
+	}
+
+	return hs, nil
+}
@@ -0,0 +1,107 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+// HID 1.11 appendix E.10, boot mouse with an added report id
+var hidMouseDescriptor = []byte{
+	0x05, 0x01, // Usage Page (Generic Desktop)
+	0x09, 0x02, // Usage (Mouse)
+	0xa1, 0x01, // Collection (Application)
+	0x85, 0x02, //   Report ID (2)
+	0x09, 0x01, //   Usage (Pointer)
+	0xa1, 0x00, //   Collection (Physical)
+	0x05, 0x09, //     Usage Page (Button)
+	0x19, 0x01, //     Usage Minimum (1)
+	0x29, 0x03, //     Usage Maximum (3)
+	0x15, 0x00, //     Logical Minimum (0)
+	0x25, 0x01, //     Logical Maximum (1)
+	0x95, 0x03, //     Report Count (3)
+	0x75, 0x01, //     Report Size (1)
+	0x81, 0x02, //     Input (Data,Var,Abs)
+	0x95, 0x01, //     Report Count (1)
+	0x75, 0x05, //     Report Size (5)
+	0x81, 0x01, //     Input (Cnst)
+	0x05, 0x01, //     Usage Page (Generic Desktop)
+	0x09, 0x30, //     Usage (X)
+	0x09, 0x31, //     Usage (Y)
+	0x15, 0x81, //     Logical Minimum (-127)
+	0x25, 0x7f, //     Logical Maximum (127)
+	0x75, 0x08, //     Report Size (8)
+	0x95, 0x02, //     Report Count (2)
+	0x81, 0x06, //     Input (Data,Var,Rel)
+	0xc0, //         End Collection
+	0xc0, //       End Collection
+}
+
+func TestParseHidReportDescriptor(t *testing.T) {
+	d, err := ParseHidReportDescriptor(hidMouseDescriptor)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []HidCollection{{UsagePage: 0x01, Usage: 0x02}}, d.Collections)
+	assert.Len(t, d.Reports, 1)
+
+	r := d.Report(2, HidReportInput)
+	assert.NotNil(t, r)
+	assert.Equal(t, 24, r.Bits())
+	assert.Equal(t, 4, r.Size())
+	assert.Len(t, r.Fields, 3)
+
+	assert.Equal(t, uint32(0x090001), r.Fields[0].UsageMin)
+	assert.Equal(t, uint32(0x090003), r.Fields[0].UsageMax)
+	assert.True(t, r.Fields[1].IsConstant())
+	assert.Equal(t, []uint32{0x010030, 0x010031}, r.Fields[2].Usages)
+	assert.Equal(t, int32(-127), r.Fields[2].LogicalMin)
+	assert.Equal(t, uint32(HidFieldVariable|HidFieldRelative), r.Fields[2].Flags)
+
+	_, err = ParseHidReportDescriptor(hidMouseDescriptor[:len(hidMouseDescriptor)-3])
+	assert.NotNil(t, err)
+
+	// Logical Minimum (0), Logical Maximum (255), Report Size (8), Report Count (1), Input (Data,Var,Abs)
+	d, err = ParseHidReportDescriptor([]byte{0x15, 0x00, 0x25, 0xff, 0x75, 0x08, 0x95, 0x01, 0x81, 0x02})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), d.Reports[0].Fields[0].LogicalMin)
+	assert.Equal(t, int32(255), d.Reports[0].Fields[0].LogicalMax)
+
+	// long item claiming 5 data bytes with only 1 present
+	_, err = ParseHidReportDescriptor([]byte{0xfe, 0x05, 0x00, 0x01})
+	assert.NotNil(t, err)
+	_, err = ParseHidReportDescriptor([]byte{0xfe, 0x01, 0x00, 0x01})
+	assert.Nil(t, err)
+
+	// unbalanced collections
+	_, err = ParseHidReportDescriptor(hidMouseDescriptor[:len(hidMouseDescriptor)-1])
+	assert.NotNil(t, err)
+	_, err = ParseHidReportDescriptor(append(append([]byte{}, hidMouseDescriptor...), 0xc0))
+	assert.NotNil(t, err)
+}
+
+func TestParseHidID(t *testing.T) {
+	id, err := ParseHidID("0003:0000046D:0000C52B")
+	assert.Nil(t, err)
+	assert.Equal(t, HidID{Bus: 0x3, Vendor: 0x46d, Product: 0xc52b}, id)
+
+	_, err = ParseHidID("0003:046D")
+	assert.NotNil(t, err)
+}
+
+func TestHidrawDevices(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	hs, err := ctx.HidrawDevices()
+	assert.Nil(t, err)
+
+	for _, h := range hs {
+		id, err := h.ID()
+		assert.Nil(t, err)
+		rd, err := h.ReportDescriptor()
+		assert.Nil(t, err)
+		spew.Dump(h.DeviceNode(), h.Name(), id, rd.Collections)
+		h.Free()
+	}
+}
@@ -0,0 +1,362 @@
+package goudev
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hwmon channel types, https://docs.kernel.org/hwmon/sysfs-interface.html
+const (
+	SensorTemp     = "temp"
+	SensorFan      = "fan"
+	SensorVoltage  = "in"
+	SensorCurrent  = "curr"
+	SensorPower    = "power"
+	SensorEnergy   = "energy"
+	SensorHumidity = "humidity"
+)
+
+// sysfs unit -> SI unit divisor
+var sensorScales = map[string]float64{
+	SensorTemp:     1000,    // m°C
+	SensorFan:      1,       // RPM
+	SensorVoltage:  1000,    // mV
+	SensorCurrent:  1000,    // mA
+	SensorPower:    1000000, // µW
+	SensorEnergy:   1000000, // µJ
+	SensorHumidity: 1000,    // m%
+}
+
+var sensorUnits = map[string]string{
+	SensorTemp:     "°C",
+	SensorFan:      "RPM",
+	SensorVoltage:  "V",
+	SensorCurrent:  "A",
+	SensorPower:    "W",
+	SensorEnergy:   "J",
+	SensorHumidity: "%",
+}
+
+// limit attributes reported in SensorChannel.Limits
+var sensorLimits = []string{"min", "max", "crit", "lcrit", "emergency", "cap"}
+
+var hwmonAttrRegexp = regexp.MustCompile(`^(temp|fan|in|curr|power|energy|humidity)(\d+)_([a-z_]+)$`)
+
+var (
+	ErrNotHwmonDevice = errors.New("udev: device is not in the hwmon subsystem")
+	ErrNotThermalZone = errors.New("udev: device is not a thermal zone")
+)
+
+// SensorChannel is one hwmon channel (e.g. temp1) or a thermal zone
+type SensorChannel struct {
+	Device string `json:"device"` // hwmon/thermal_zone sysname
+	Type   string `json:"type"`
+	Index  int    `json:"index"`
+	Label  string `json:"label,omitempty"`
+	// limits in SI units
+	Limits map[string]float64 `json:"limits,omitempty"`
+	path   string
+}
+
+func (s *SensorChannel) Name() string {
+	if s.Label != "" {
+		return s.Label
+	}
+	return s.Type + strconv.Itoa(s.Index)
+}
+
+func (s *SensorChannel) Unit() string {
+	return sensorUnits[s.Type]
+}
+
+// Value reads the current value from sysfs in SI units; not cached like Device.GetAttribute
+func (s *SensorChannel) Value() (float64, error) {
+	return readSensorFile(s.path, s.Type)
+}
+
+func readSensorFile(path, typ string) (float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(v) / sensorScales[typ], nil
+}
+
+// hwmonChannels scans a hwmon directory for <type><n>_input channels; power
+// sensors without power<n>_input use power<n>_average
+func hwmonChannels(dir, device string) ([]*SensorChannel, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+	for _, e := range entries {
+		files[e.Name()] = true
+	}
+
+	var cs []*SensorChannel
+	seen := map[string]bool{}
+	for _, e := range entries {
+		m := hwmonAttrRegexp.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		prefix := m[1] + m[2]
+		if seen[prefix] {
+			continue
+		}
+
+		input := prefix + "_input"
+		if !files[input] && m[1] == SensorPower && files[prefix+"_average"] {
+			input = prefix + "_average"
+		}
+		if !files[input] {
+			continue
+		}
+		seen[prefix] = true
+
+		c := &SensorChannel{
+			Device: device,
+			Type:   m[1],
+			path:   filepath.Join(dir, input),
+		}
+		c.Index, _ = strconv.Atoi(m[2])
+		if data, err := os.ReadFile(filepath.Join(dir, prefix+"_label")); err == nil {
+			c.Label = strings.TrimSpace(string(data))
+		}
+		for _, l := range sensorLimits {
+			if !files[prefix+"_"+l] {
+				continue
+			}
+			if v, err := readSensorFile(filepath.Join(dir, prefix+"_"+l), c.Type); err == nil {
+				if c.Limits == nil {
+					c.Limits = map[string]float64{}
+				}
+				c.Limits[l] = v
+			}
+		}
+
+		cs = append(cs, c)
+	}
+
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Type != cs[j].Type {
+			return cs[i].Type < cs[j].Type
+		}
+		return cs[i].Index < cs[j].Index
+	})
+
+	return cs, nil
+}
+
+// HwmonDevice is a typed view of a hwmon subsystem Device
+type HwmonDevice struct {
+	*Device
+}
+
+func NewHwmonDevice(d *Device) (*HwmonDevice, error) {
+	if d.Subsystem() != "hwmon" {
+		return nil, ErrNotHwmonDevice
+	}
+
+	return &HwmonDevice{d}, nil
+}
+
+// Name returns the driver chosen name, e.g. coretemp, k10temp, nvme or amdgpu
+func (h *HwmonDevice) Name() string {
+	return strings.TrimSpace(h.GetAttribute("name"))
+}
+
+// BusDevice returns the device being monitored, e.g. the PCI GPU, the nvme
+// controller or the coretemp platform device
+func (h *HwmonDevice) BusDevice() (*Device, error) {
+	return h.Parent()
+}
+
+func (h *HwmonDevice) Channels() ([]*SensorChannel, error) {
+	return hwmonChannels(h.SysPath(), h.SysName())
+}
+
+// HwmonDevices returns all hwmon devices ordered by name
+func (c *Context) HwmonDevices() ([]*HwmonDevice, error) {
+	ds, err := c.subsystemDevices("hwmon", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	hs := make([]*HwmonDevice, 0, len(ds))
+	for _, d := range ds {
+		hs = append(hs, &HwmonDevice{d})
+	}
+
+	return hs, nil
+}
+
+type ThermalTrip struct {
+	Type        string  `json:"type"` // active, passive, hot, critical
+	Temperature float64 `json:"temperature"`
+}
+
+// ThermalZone is a typed view of a thermal_zone<n> Device of the thermal subsystem
+type ThermalZone struct {
+	*Device
+}
+
+func NewThermalZone(d *Device) (*ThermalZone, error) {
+	if d.Subsystem() != "thermal" || !strings.HasPrefix(d.SysName(), "thermal_zone") {
+		return nil, ErrNotThermalZone
+	}
+
+	return &ThermalZone{d}, nil
+}
+
+// Type returns the zone type, e.g. x86_pkg_temp, acpitz or cpu-thermal
+func (z *ThermalZone) Type() string {
+	return strings.TrimSpace(z.GetAttribute("type"))
+}
+
+func (z *ThermalZone) Channel() *SensorChannel {
+	return &SensorChannel{
+		Device: z.SysName(),
+		Type:   SensorTemp,
+		Label:  z.Type(),
+		path:   filepath.Join(z.SysPath(), "temp"),
+	}
+}
+
+func (z *ThermalZone) Temperature() (float64, error) {
+	return z.Channel().Value()
+}
+
+func (z *ThermalZone) TripPoints() []ThermalTrip {
+	var ts []ThermalTrip
+	for i := 0; ; i++ {
+		prefix := "trip_point_" + strconv.Itoa(i) + "_"
+		typ := strings.TrimSpace(z.GetAttribute(prefix + "type"))
+		if typ == "" {
+			break
+		}
+
+		v, err := readSensorFile(filepath.Join(z.SysPath(), prefix+"temp"), SensorTemp)
+		if err != nil {
+			continue
+		}
+		ts = append(ts, ThermalTrip{Type: typ, Temperature: v})
+	}
+
+	return ts
+}
+
+// ThermalZones returns all thermal zones ordered by name, cooling devices are skipped
+func (c *Context) ThermalZones() ([]*ThermalZone, error) {
+	ds, err := c.subsystemDevices("thermal", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	zs := make([]*ThermalZone, 0, len(ds))
+	for _, d := range ds {
+		if z, err := NewThermalZone(d); err == nil {
+			zs = append(zs, z)
+			continue
+		}
+		d.Free()
+	}
+
+	return zs, nil
+}
+
+// Sensors returns the channels of all hwmon devices followed by the thermal zones
+func (c *Context) Sensors() ([]*SensorChannel, error) {
+	hs, err := c.HwmonDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	var cs []*SensorChannel
+	for _, h := range hs {
+		hcs, err := h.Channels()
+		h.Free()
+		if err == nil {
+			cs = append(cs, hcs...)
+		}
+	}
+
+	zs, err := c.ThermalZones()
+	if err != nil {
+		return nil, err
+	}
+	for _, z := range zs {
+		cs = append(cs, z.Channel())
+		z.Free()
+	}
+
+	return cs, nil
+}
+
+type SensorReading struct {
+	Channel *SensorChannel
+	Value   float64
+	Err     error
+}
+
+type SensorSample struct {
+	Time     time.Time
+	Readings []SensorReading
+}
+
+func ReadSensors(cs []*SensorChannel) *SensorSample {
+	s := &SensorSample{
+		Time:     time.Now(),
+		Readings: make([]SensorReading, len(cs)),
+	}
+	for i, c := range cs {
+		v, err := c.Value()
+		s.Readings[i] = SensorReading{Channel: c, Value: v, Err: err}
+	}
+
+	return s
+}
+
+// PollSensors reads cs every interval, starting immediately, until ctx is done
+// and then closes the channel. Ticks missed by a slow receiver are dropped.
+func PollSensors(ctx context.Context, cs []*SensorChannel, interval time.Duration) <-chan *SensorSample {
+	out := make(chan *SensorSample)
+
+	go func() {
+		defer close(out)
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case out <- ReadSensors(cs):
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,79 @@
+package goudev
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHwmonChannels(t *testing.T) {
+	dir := t.TempDir()
+	for name, v := range map[string]string{
+		"name":           "nvme",
+		"temp1_input":    "38850",
+		"temp1_label":    "Composite",
+		"temp1_crit":     "84850",
+		"temp1_min":      "-273150",
+		"temp2_input":    "41850",
+		"fan1_input":     "1200",
+		"in0_input":      "1050",
+		"power1_average": "12500000",
+		"curr1_max":      "5000",
+	} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(v+"\n"), 0644))
+	}
+
+	cs, err := hwmonChannels(dir, "hwmon0")
+	assert.Nil(t, err)
+	assert.Len(t, cs, 5) // curr1 has no input
+
+	names := []string{}
+	for _, c := range cs {
+		names = append(names, c.Name())
+	}
+	assert.Equal(t, []string{"fan1", "in0", "power1", "Composite", "temp2"}, names)
+
+	temp := cs[3]
+	assert.Equal(t, map[string]float64{"crit": 84.85, "min": -273.15}, temp.Limits)
+	v, err := temp.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, 38.85, v)
+	assert.Equal(t, "°C", temp.Unit())
+
+	v, err = cs[1].Value()
+	assert.Nil(t, err)
+	assert.Equal(t, 1.05, v)
+	v, err = cs[2].Value()
+	assert.Nil(t, err)
+	assert.Equal(t, 12.5, v)
+
+	cctx, cancel := context.WithCancel(context.Background())
+	ch := PollSensors(cctx, cs, 10*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		s := <-ch
+		assert.Len(t, s.Readings, 5)
+		assert.Nil(t, s.Readings[0].Err)
+		assert.Equal(t, 1200.0, s.Readings[0].Value)
+	}
+	cancel()
+	for range ch {
+	}
+}
+
+func TestSensors(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	cs, err := ctx.Sensors()
+	assert.Nil(t, err)
+
+	s := ReadSensors(cs)
+	for _, r := range s.Readings {
+		spew.Dump(r.Channel.Device, r.Channel.Name(), r.Value, r.Channel.Unit(), r.Err)
+	}
+}
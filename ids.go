@@ -0,0 +1,339 @@
+package goudev
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// searched in order by LoadPciIDs/LoadUsbIDs without an explicit path
+	DefaultPciIDsPaths = []string{"/usr/share/hwdata/pci.ids", "/usr/share/misc/pci.ids", "/usr/share/pci.ids", "/usr/share/misc/pci.ids.gz"}
+	DefaultUsbIDsPaths = []string{"/usr/share/hwdata/usb.ids", "/usr/share/misc/usb.ids", "/usr/share/usb.ids", "/var/lib/usbutils/usb.ids"}
+
+	ErrIDsNotFound = errors.New("udev: no ids database found")
+)
+
+type idDevice struct {
+	name       string
+	subsystems map[uint32]string // subvendor<<16 | subdevice
+}
+
+type idVendor struct {
+	name    string
+	devices map[uint16]*idDevice
+}
+
+type idSubclass struct {
+	name    string
+	progIfs map[uint8]string
+}
+
+type idClass struct {
+	name       string
+	subclasses map[uint8]*idSubclass
+}
+
+// IDDatabase holds a parsed pci.ids or usb.ids file (https://pci-ids.ucw.cz, http://www.linux-usb.org/usb-ids.html).
+// For usb.ids, subclass and prog-if are the USB subclass and protocol.
+type IDDatabase struct {
+	vendors map[uint16]*idVendor
+	classes map[uint8]*idClass
+}
+
+// NewIDDatabase returns an empty database, all lookups fall back to numeric names
+func NewIDDatabase() *IDDatabase {
+	return &IDDatabase{
+		vendors: map[uint16]*idVendor{},
+		classes: map[uint8]*idClass{},
+	}
+}
+
+// LoadIDs parses the first existing file of paths, gzip compressed if it ends with .gz
+func LoadIDs(paths ...string) (*IDDatabase, error) {
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		var r io.Reader = f
+		if strings.HasSuffix(p, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return nil, err
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		return ParseIDs(r)
+	}
+
+	return nil, ErrIDsNotFound
+}
+
+// LoadPciIDs loads path, or the first of DefaultPciIDsPaths if path is empty
+func LoadPciIDs(path string) (*IDDatabase, error) {
+	if path != "" {
+		return LoadIDs(path)
+	}
+
+	return LoadIDs(DefaultPciIDsPaths...)
+}
+
+// LoadUsbIDs loads path, or the first of DefaultUsbIDsPaths if path is empty
+func LoadUsbIDs(path string) (*IDDatabase, error) {
+	if path != "" {
+		return LoadIDs(path)
+	}
+
+	return LoadIDs(DefaultUsbIDsPaths...)
+}
+
+func parseIDHex(s string, bits int) (uint64, bool) {
+	v, err := strconv.ParseUint(s, 16, bits)
+	return v, err == nil
+}
+
+// splitIDLine splits "8086  Intel Corporation" into id and name
+func splitIDLine(line string) (string, string) {
+	id, name, _ := strings.Cut(line, " ")
+	return id, strings.TrimSpace(name)
+}
+
+// ParseIDs parses the pci.ids/usb.ids format; sections other than vendors and
+// classes (usb.ids AT, HID, HUT, L, ...) are skipped
+func ParseIDs(r io.Reader) (*IDDatabase, error) {
+	db := NewIDDatabase()
+
+	var vendor *idVendor
+	var device *idDevice
+	var class *idClass
+	var subclass *idSubclass
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		depth := len(line) - len(strings.TrimLeft(line, "\t"))
+		line = line[depth:]
+
+		switch depth {
+		case 0:
+			vendor, device, class, subclass = nil, nil, nil, nil
+
+			if strings.HasPrefix(line, "C ") {
+				id, name := splitIDLine(line[2:])
+				if v, ok := parseIDHex(id, 8); ok {
+					class = &idClass{name: name, subclasses: map[uint8]*idSubclass{}}
+					db.classes[uint8(v)] = class
+				}
+				continue
+			}
+
+			id, name := splitIDLine(line)
+			if v, ok := parseIDHex(id, 16); ok && len(id) == 4 {
+				vendor = &idVendor{name: name, devices: map[uint16]*idDevice{}}
+				db.vendors[uint16(v)] = vendor
+			}
+		case 1:
+			id, name := splitIDLine(line)
+			switch {
+			case vendor != nil:
+				if v, ok := parseIDHex(id, 16); ok {
+					device = &idDevice{name: name, subsystems: map[uint32]string{}}
+					vendor.devices[uint16(v)] = device
+				}
+			case class != nil:
+				if v, ok := parseIDHex(id, 8); ok {
+					subclass = &idSubclass{name: name, progIfs: map[uint8]string{}}
+					class.subclasses[uint8(v)] = subclass
+				}
+			}
+		case 2:
+			switch {
+			case device != nil:
+				// "subvendor subdevice  name", usb.ids has interface lines "ii  name" here
+				fields := strings.SplitN(line, " ", 3)
+				if len(fields) < 3 {
+					continue
+				}
+				sv, ok1 := parseIDHex(fields[0], 16)
+				sd, ok2 := parseIDHex(fields[1], 16)
+				if ok1 && ok2 {
+					device.subsystems[uint32(sv)<<16|uint32(sd)] = strings.TrimSpace(fields[2])
+				}
+			case subclass != nil:
+				id, name := splitIDLine(line)
+				if v, ok := parseIDHex(id, 8); ok {
+					subclass.progIfs[uint8(v)] = name
+				}
+			}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *IDDatabase) VendorName(vendor uint16) string {
+	if v, ok := db.vendors[vendor]; ok {
+		return v.name
+	}
+
+	return ""
+}
+
+func (db *IDDatabase) DeviceName(vendor, device uint16) string {
+	if v, ok := db.vendors[vendor]; ok {
+		if d, ok := v.devices[device]; ok {
+			return d.name
+		}
+	}
+
+	return ""
+}
+
+func (db *IDDatabase) SubsystemName(vendor, device, subvendor, subdevice uint16) string {
+	if v, ok := db.vendors[vendor]; ok {
+		if d, ok := v.devices[device]; ok {
+			return d.subsystems[uint32(subvendor)<<16|uint32(subdevice)]
+		}
+	}
+
+	return ""
+}
+
+func (db *IDDatabase) ClassName(class uint8) string {
+	if c, ok := db.classes[class]; ok {
+		return c.name
+	}
+
+	return ""
+}
+
+func (db *IDDatabase) SubclassName(class, subclass uint8) string {
+	if c, ok := db.classes[class]; ok {
+		if s, ok := c.subclasses[subclass]; ok {
+			return s.name
+		}
+	}
+
+	return ""
+}
+
+func (db *IDDatabase) ProgIfName(class, subclass, progIf uint8) string {
+	if c, ok := db.classes[class]; ok {
+		if s, ok := c.subclasses[subclass]; ok {
+			return s.progIfs[progIf]
+		}
+	}
+
+	return ""
+}
+
+// IDNames are the database names of a device, lspci -vmm style. Unknown
+// entries are filled like lspci does: "Device 1042", "Vendor 1af4".
+type IDNames struct {
+	Vendor          string
+	Device          string
+	SubsystemVendor string
+	Subsystem       string
+	Class           string
+	ProgIf          string
+}
+
+func (db *IDDatabase) names(vendor, device, subvendor, subdevice uint16, class, subclass, progIf uint8) IDNames {
+	n := IDNames{
+		Vendor:          db.VendorName(vendor),
+		Device:          db.DeviceName(vendor, device),
+		SubsystemVendor: db.VendorName(subvendor),
+		Subsystem:       db.SubsystemName(vendor, device, subvendor, subdevice),
+		Class:           db.SubclassName(class, subclass),
+		ProgIf:          db.ProgIfName(class, subclass, progIf),
+	}
+	if n.Vendor == "" {
+		n.Vendor = fmt.Sprintf("Vendor %04x", vendor)
+	}
+	if n.Device == "" {
+		n.Device = fmt.Sprintf("Device %04x", device)
+	}
+	if subvendor != 0 || subdevice != 0 {
+		if n.SubsystemVendor == "" {
+			n.SubsystemVendor = fmt.Sprintf("Vendor %04x", subvendor)
+		}
+		if n.Subsystem == "" {
+			n.Subsystem = fmt.Sprintf("Device %04x", subdevice)
+		}
+	}
+	if n.Class == "" {
+		n.Class = db.ClassName(class)
+	}
+	if n.Class == "" {
+		n.Class = fmt.Sprintf("Class %02x%02x", class, subclass)
+	}
+
+	return n
+}
+
+// PciNames looks up a pci device
+func (db *IDDatabase) PciNames(p *PciDevice) IDNames {
+	c := p.Class()
+	n := db.names(p.VendorID(), p.DeviceID(), p.SubsystemVendorID(), p.SubsystemDeviceID(), uint8(c>>16), uint8(c>>8), uint8(c))
+	if db.ClassName(uint8(c>>16)) == "" {
+		// builtin table when the database lacks classes
+		n.Class = PciClassName(c)
+	}
+	if n.ProgIf == "" {
+		n.ProgIf = PciProgIfName(c)
+	}
+	return n
+}
+
+// UsbNames looks up a usb_device, the class is the device class
+func (db *IDDatabase) UsbNames(u *UsbDevice) IDNames {
+	n := db.names(u.VendorID(), u.ProductID(), 0, 0, u.Class(), u.attributeHex8("bDeviceSubClass"), u.attributeHex8("bDeviceProtocol"))
+	if u.Class() == 0 {
+		// defined per interface
+		n.Class = ""
+	} else if db.ClassName(u.Class()) == "" {
+		n.Class = UsbClassName(u.Class())
+	}
+	return n
+}
+
+// DeviceNames looks up a pci device or usb_device, or the pci/usb device d belongs to;
+// db has to be the matching pci.ids or usb.ids
+func (db *IDDatabase) DeviceNames(d *Device) (IDNames, error) {
+	if p, err := NewPciDevice(d); err == nil {
+		return db.PciNames(p), nil
+	}
+	if u, err := NewUsbDevice(d); err == nil {
+		return db.UsbNames(u), nil
+	}
+
+	if u, err := UsbDeviceOf(d); err == nil {
+		defer u.Free()
+		return db.UsbNames(u), nil
+	}
+	if p, err := d.FindParent("pci"); err == nil {
+		defer p.Free()
+		return db.PciNames(&PciDevice{p}), nil
+	}
+
+	return IDNames{}, ErrNoParentDevice
+}
@@ -0,0 +1,74 @@
+package goudev
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPciIDs = `# pci.ids excerpt
+1af4  Red Hat, Inc.
+	1000  Virtio network device
+		1af4 0001  Virtio network device
+	1042  Virtio 1.0 block device
+8086  Intel Corporation
+	1572  Ethernet Controller X710 for 10GbE SFP+
+		8086 0001  Ethernet Converged Network Adapter X710-4
+C 01  Mass storage controller
+	00  SCSI storage controller
+	08  Non-Volatile memory controller
+		02  NVM Express
+C 02  Network controller
+	00  Ethernet controller
+`
+
+const testUsbIDs = `0403  Future Technology Devices International, Ltd
+	6001  FT232 Serial (UART) IC
+		00  interface name
+C 09  Hub
+	00  Unused
+		00  Full speed (or root) hub
+AT 0001  Audio Terminal
+HUT 01  Generic Desktop Controls
+	000  Undefined
+`
+
+func TestParsePciIDs(t *testing.T) {
+	db, err := ParseIDs(strings.NewReader(testPciIDs))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "Red Hat, Inc.", db.VendorName(0x1af4))
+	assert.Equal(t, "Virtio 1.0 block device", db.DeviceName(0x1af4, 0x1042))
+	assert.Equal(t, "Ethernet Converged Network Adapter X710-4", db.SubsystemName(0x8086, 0x1572, 0x8086, 0x0001))
+	assert.Equal(t, "Mass storage controller", db.ClassName(0x01))
+	assert.Equal(t, "Non-Volatile memory controller", db.SubclassName(0x01, 0x08))
+	assert.Equal(t, "NVM Express", db.ProgIfName(0x01, 0x08, 0x02))
+
+	n := db.names(0x1af4, 0x1234, 0, 0, 0x02, 0x80, 0)
+	assert.Equal(t, "Red Hat, Inc.", n.Vendor)
+	assert.Equal(t, "Device 1234", n.Device)
+	assert.Equal(t, "", n.SubsystemVendor)
+	assert.Equal(t, "Network controller", n.Class)
+
+	n = db.names(0xabcd, 0x0001, 0xabcd, 0x0002, 0xee, 0x00, 0)
+	assert.Equal(t, "Vendor abcd", n.Vendor)
+	assert.Equal(t, "Device 0002", n.Subsystem)
+	assert.Equal(t, "Class ee00", n.Class)
+}
+
+func TestParseUsbIDs(t *testing.T) {
+	db, err := ParseIDs(strings.NewReader(testUsbIDs))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "Future Technology Devices International, Ltd", db.VendorName(0x0403))
+	assert.Equal(t, "FT232 Serial (UART) IC", db.DeviceName(0x0403, 0x6001))
+	assert.Equal(t, "Hub", db.ClassName(0x09))
+	assert.Equal(t, "Full speed (or root) hub", db.ProgIfName(0x09, 0x00, 0x00))
+	assert.Equal(t, "", db.VendorName(0x0001))
+}
+
+func TestLoadIDsNotFound(t *testing.T) {
+	_, err := LoadIDs("/nonexistent/pci.ids")
+	assert.Equal(t, ErrIDsNotFound, err)
+}
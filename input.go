@@ -0,0 +1,410 @@
+package goudev
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// include/uapi/linux/input-event-codes.h
+const (
+	EvKey = 0x01
+	EvRel = 0x02
+	EvAbs = 0x03
+	EvMsc = 0x04
+	EvSw  = 0x05
+	EvLed = 0x11
+	EvFf  = 0x15
+
+	InputPropDirect        = 0x01
+	InputPropPointingStick = 0x05
+	InputPropAccelerometer = 0x06
+
+	absX           = 0x00
+	absY           = 0x01
+	absZ           = 0x02
+	absRx          = 0x03
+	absPressure    = 0x18
+	absMtSlot      = 0x2f
+	absMtPositionX = 0x35
+	absMtPositionY = 0x36
+
+	relX      = 0x00
+	relY      = 0x01
+	relHwheel = 0x06
+	relWheel  = 0x08
+
+	keyEsc            = 1
+	keyOk             = 0x160
+	keyAlsToggle      = 0x230
+	btnMisc           = 0x100
+	btn0              = 0x100
+	btn1              = 0x101
+	btnMouse          = 0x110
+	btnJoystick       = 0x120
+	btnDigi           = 0x140
+	btnToolPen        = 0x140
+	btnToolFinger     = 0x145
+	btnTouch          = 0x14a
+	btnStylus         = 0x14b
+	btnDpadUp         = 0x220
+	btnDpadRight      = 0x223
+	btnTriggerHappy1  = 0x2c0
+	btnTriggerHappy40 = 0x2e7
+
+	busI2C = 0x18
+)
+
+// a random pick of keys found on keyboards, used to tell keyboards with stray joystick buttons apart
+var wellKnownKeyboardKeys = []int{29, 58, 69, 110, 113, 140, 144, 155, 164, 224}
+
+var (
+	ErrNotInputDevice = errors.New("udev: device is not an input device with capabilities")
+)
+
+// InputBitmap is a decoded capabilities bitmap, bit n set means code n is supported
+type InputBitmap []uint64
+
+// ParseInputBitmap parses the sysfs format: hex longs, most significant first, e.g. "120013" or "1000000000 e080ffdf01cfffff".
+// A long is assumed to be as wide as on this host, 32 bits on 32-bit kernels.
+func ParseInputBitmap(s string) (InputBitmap, error) {
+	return parseInputBitmap(s, bits.UintSize)
+}
+
+func parseInputBitmap(s string, wordBits int) (InputBitmap, error) {
+	fields := strings.Fields(s)
+	b := make(InputBitmap, (len(fields)*wordBits+63)/64)
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 16, wordBits)
+		if err != nil {
+			return nil, fmt.Errorf("udev: bad input bitmap %q", s)
+		}
+
+		base := (len(fields) - 1 - i) * wordBits
+		for v != 0 {
+			n := bits.TrailingZeros64(v)
+			code := base + n
+			b[code/64] |= 1 << uint(code%64)
+			v &^= 1 << uint(n)
+		}
+	}
+
+	return b, nil
+}
+
+func (b InputBitmap) Has(code int) bool {
+	i := code / 64
+	return code >= 0 && i < len(b) && b[i]&(1<<uint(code%64)) != 0
+}
+
+// Codes returns the set codes in ascending order
+func (b InputBitmap) Codes() []int {
+	var cs []int
+	for i, w := range b {
+		for w != 0 {
+			n := bits.TrailingZeros64(w)
+			cs = append(cs, i*64+n)
+			w &^= 1 << uint(n)
+		}
+	}
+	sort.Ints(cs)
+	return cs
+}
+
+func (b InputBitmap) hasAny(from, to int) bool {
+	for c := from; c <= to; c++ {
+		if b.Has(c) {
+			return true
+		}
+	}
+	return false
+}
+
+type InputCapabilities struct {
+	Ev    InputBitmap
+	Key   InputBitmap
+	Abs   InputBitmap
+	Rel   InputBitmap
+	Sw    InputBitmap
+	Led   InputBitmap
+	Msc   InputBitmap
+	Ff    InputBitmap
+	Props InputBitmap
+}
+
+// InputClass is the ID_INPUT_* classification
+type InputClass struct {
+	Key           bool // ID_INPUT_KEY
+	Keyboard      bool // ID_INPUT_KEYBOARD
+	Mouse         bool // ID_INPUT_MOUSE
+	Touchpad      bool // ID_INPUT_TOUCHPAD
+	Touchscreen   bool // ID_INPUT_TOUCHSCREEN
+	Joystick      bool // ID_INPUT_JOYSTICK
+	Tablet        bool // ID_INPUT_TABLET
+	TabletPad     bool // ID_INPUT_TABLET_PAD
+	Accelerometer bool // ID_INPUT_ACCELEROMETER
+	PointingStick bool // ID_INPUT_POINTINGSTICK
+	Switch        bool // ID_INPUT_SWITCH
+}
+
+// ClassifyInput reproduces udev's input_id builtin,
+// https://github.com/systemd/systemd/blob/main/src/udev/udev-builtin-input_id.c
+func ClassifyInput(c *InputCapabilities, bustype uint16) InputClass {
+	var ic InputClass
+
+	isPointer := classifyPointers(c, bustype, &ic)
+	ic.Key, ic.Keyboard = classifyKeys(c)
+
+	// some evdev nodes have only a scrollwheel
+	if !isPointer && !ic.Key && c.Ev.Has(EvRel) && (c.Rel.Has(relWheel) || c.Rel.Has(relHwheel)) {
+		ic.Key = true
+	}
+	ic.Switch = c.Ev.Has(EvSw)
+
+	return ic
+}
+
+func classifyPointers(c *InputCapabilities, bustype uint16, ic *InputClass) bool {
+	hasKeys := c.Ev.Has(EvKey)
+	hasAbs := c.Abs.Has(absX) && c.Abs.Has(absY)
+	has3d := hasAbs && c.Abs.Has(absZ)
+
+	if c.Props.Has(InputPropAccelerometer) || (!hasKeys && has3d) {
+		ic.Accelerometer = true
+		return true
+	}
+
+	isPointingStick := c.Props.Has(InputPropPointingStick)
+	hasStylus := c.Key.Has(btnStylus)
+	hasPen := c.Key.Has(btnToolPen)
+	fingerButNoPen := c.Key.Has(btnToolFinger) && !hasPen
+	hasMouseButton := c.Key.hasAny(btnMouse, btnJoystick-1)
+	hasRel := c.Ev.Has(EvRel) && c.Rel.Has(relX) && c.Rel.Has(relY)
+	hasMt := c.Abs.Has(absMtPositionX) && c.Abs.Has(absMtPositionY)
+	// devices claiming all abs axes are not multitouch
+	if hasMt && c.Abs.Has(absMtSlot) && c.Abs.Has(absMtSlot-1) {
+		hasMt = false
+	}
+	isDirect := c.Props.Has(InputPropDirect)
+	hasTouch := c.Key.Has(btnTouch)
+	hasPadButtons := c.Key.Has(btn0) && c.Key.Has(btn1) && !hasPen
+	hasWheel := c.Ev.Has(EvRel) && (c.Rel.Has(relWheel) || c.Rel.Has(relHwheel))
+
+	// mice with more than 16 buttons run into the joystick range
+	hasJoystick := false
+	if !c.Key.Has(btnJoystick - 1) {
+		hasJoystick = c.Key.hasAny(btnJoystick, btnDigi-1) ||
+			c.Key.hasAny(btnTriggerHappy1, btnTriggerHappy40) ||
+			c.Key.hasAny(btnDpadUp, btnDpadRight)
+	}
+	hasJoystick = hasJoystick || c.Abs.hasAny(absRx, absPressure-1)
+
+	var isTablet, isTabletPad, isTouchpad, isTouchscreen, isJoystick, isMouse, isAbsMouse bool
+	if hasAbs {
+		switch {
+		case hasStylus || hasPen:
+			isTablet = true
+		case fingerButNoPen && !isDirect:
+			isTouchpad = true
+		case hasMouseButton:
+			// VMware's USB mouse has absolute axes, but no touch/pressure button
+			isAbsMouse = true
+		case hasTouch || isDirect:
+			isTouchscreen = true
+		case hasJoystick:
+			isJoystick = true
+		}
+	} else if hasJoystick {
+		isJoystick = true
+	}
+
+	if hasMt {
+		switch {
+		case hasStylus || hasPen:
+			isTablet = true
+		case fingerButNoPen && !isDirect:
+			isTouchpad = true
+		case hasTouch || isDirect:
+			isTouchscreen = true
+		}
+	}
+
+	if isTablet && hasPadButtons {
+		isTabletPad = true
+	}
+	if hasPadButtons && hasWheel && !hasRel {
+		isTablet, isTabletPad = true, true
+	}
+
+	if !isTablet && !isTouchpad && !isJoystick && hasMouseButton && (hasRel || !hasAbs) {
+		isMouse = true
+	}
+
+	// there is no such thing as an i2c mouse
+	if isMouse && bustype == busI2C {
+		isPointingStick = true
+	}
+
+	// keyboards with random joystick buttons
+	if isJoystick {
+		numKeys, numWellKnown := 0, 0
+		for k := 0; k < btnMisc; k++ {
+			if c.Key.Has(k) {
+				numKeys++
+			}
+		}
+		for _, k := range wellKnownKeyboardKeys {
+			if c.Key.Has(k) {
+				numWellKnown++
+			}
+		}
+		if numWellKnown >= 4 || numKeys >= 10 {
+			isJoystick = false
+		}
+	}
+
+	ic.PointingStick = isPointingStick
+	ic.Mouse = isMouse || isAbsMouse
+	ic.Touchpad = isTouchpad
+	ic.Touchscreen = isTouchscreen
+	ic.Joystick = isJoystick
+	ic.Tablet = isTablet
+	ic.TabletPad = isTabletPad
+
+	return isTablet || isMouse || isAbsMouse || isTouchpad || isTouchscreen || isJoystick || isPointingStick
+}
+
+func classifyKeys(c *InputCapabilities) (key, keyboard bool) {
+	if !c.Ev.Has(EvKey) {
+		return false, false
+	}
+
+	// only KEY_*, not BTN_*
+	key = c.Key.hasAny(0, btnMisc-1) ||
+		c.Key.hasAny(keyOk, btnDpadUp-1) ||
+		c.Key.hasAny(keyAlsToggle, btnTriggerHappy1-1)
+
+	// ESC, numbers, and Q to D make a full keyboard; KEY_RESERVED is not tested
+	keyboard = c.Key.hasAll(keyEsc, 31)
+
+	return key, keyboard
+}
+
+func (b InputBitmap) hasAll(from, to int) bool {
+	for c := from; c <= to; c++ {
+		if !b.Has(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// InputDevice is a typed view of an input subsystem Device with capabilities,
+// the inputN parent of the event/mouse/js nodes
+type InputDevice struct {
+	*Device
+}
+
+func NewInputDevice(d *Device) (*InputDevice, error) {
+	if d.Subsystem() != "input" || d.GetAttribute("capabilities/ev") == "" {
+		return nil, ErrNotInputDevice
+	}
+
+	return &InputDevice{d}, nil
+}
+
+// InputDeviceOf returns the input device of an event, mouse or js node
+func InputDeviceOf(d *Device) (*InputDevice, error) {
+	p, err := d.FindParent("input")
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := NewInputDevice(p)
+	if err != nil {
+		p.Free()
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *InputDevice) Name() string {
+	return strings.Trim(strings.TrimSpace(i.GetAttribute("name")), `"`)
+}
+
+func (i *InputDevice) Bustype() uint16 {
+	return i.attributeHex16("id/bustype")
+}
+
+func (i *InputDevice) VendorID() uint16 {
+	return i.attributeHex16("id/vendor")
+}
+
+func (i *InputDevice) ProductID() uint16 {
+	return i.attributeHex16("id/product")
+}
+
+func (i *InputDevice) Capabilities() (*InputCapabilities, error) {
+	c := &InputCapabilities{}
+	for _, f := range []struct {
+		attribute string
+		bitmap    *InputBitmap
+	}{
+		{"capabilities/ev", &c.Ev},
+		{"capabilities/key", &c.Key},
+		{"capabilities/abs", &c.Abs},
+		{"capabilities/rel", &c.Rel},
+		{"capabilities/sw", &c.Sw},
+		{"capabilities/led", &c.Led},
+		{"capabilities/msc", &c.Msc},
+		{"capabilities/ff", &c.Ff},
+		{"properties", &c.Props},
+	} {
+		b, err := ParseInputBitmap(i.GetAttribute(f.attribute))
+		if err != nil {
+			return nil, err
+		}
+		*f.bitmap = b
+	}
+
+	return c, nil
+}
+
+// Classify computes the ID_INPUT_* classification in Go, without udevd
+func (i *InputDevice) Classify() (InputClass, error) {
+	c, err := i.Capabilities()
+	if err != nil {
+		return InputClass{}, err
+	}
+
+	return ClassifyInput(c, i.Bustype()), nil
+}
+
+// Handlers returns the nodes of the device, e.g. event3, mouse0, js0
+func (i *InputDevice) Handlers() ([]*Device, error) {
+	return i.Children(func(p *Device) FilterFn {
+		return func(td *Device) bool {
+			return td.SysPath() != p.SysPath() && td.DeviceNode() != ""
+		}
+	})
+}
+
+// EventNode returns the /dev/input/event* node
+func (i *InputDevice) EventNode() (string, error) {
+	hs, err := i.Handlers()
+	if err != nil {
+		return "", err
+	}
+	defer FreeDevices(hs)
+
+	for _, h := range hs {
+		if strings.HasPrefix(h.SysName(), "event") {
+			return h.DeviceNode(), nil
+		}
+	}
+
+	return "", ErrNotInputDevice
+}
@@ -0,0 +1,109 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustInputCapabilities(t *testing.T, ev, key, abs, rel, props string) *InputCapabilities {
+	c := &InputCapabilities{}
+	var err error
+	for _, f := range []struct {
+		s string
+		b *InputBitmap
+	}{{ev, &c.Ev}, {key, &c.Key}, {abs, &c.Abs}, {rel, &c.Rel}, {props, &c.Props}} {
+		*f.b, err = ParseInputBitmap(f.s)
+		assert.Nil(t, err)
+	}
+	return c
+}
+
+func TestParseInputBitmap(t *testing.T) {
+	b, err := ParseInputBitmap("1000000000 e080ffdf01cfffff")
+	assert.Nil(t, err)
+	assert.True(t, b.Has(0))
+	assert.True(t, b.Has(64+36))
+	assert.False(t, b.Has(64+37))
+	assert.False(t, b.Has(1000))
+
+	b, err = ParseInputBitmap("120013")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 4, 17, 20}, b.Codes())
+
+	_, err = ParseInputBitmap("xyz")
+	assert.NotNil(t, err)
+
+	// 32-bit kernels print 32-bit longs
+	b, err = parseInputBitmap("1 0", 32)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{32}, b.Codes())
+	assert.True(t, b.Has(32))
+
+	b, err = parseInputBitmap("10 e080ffdf 1cfffff", 32)
+	assert.Nil(t, err)
+	assert.True(t, b.Has(64+4))
+	assert.True(t, b.Has(32+31))
+	assert.False(t, b.Has(32+28))
+
+	_, err = parseInputBitmap("100000000", 32)
+	assert.NotNil(t, err)
+}
+
+func TestClassifyInput(t *testing.T) {
+	// AT Translated Set 2 keyboard
+	kbd := mustInputCapabilities(t, "120013", "402000000 3803078f800d001 feffffdfffefffff fffffffffffffffe", "0", "0", "0")
+	assert.Equal(t, InputClass{Key: true, Keyboard: true}, ClassifyInput(kbd, 0x11))
+
+	// USB optical mouse
+	mouse := mustInputCapabilities(t, "17", "1f0000 0 0 0 0", "0", "903", "0")
+	assert.Equal(t, InputClass{Mouse: true}, ClassifyInput(mouse, 0x03))
+
+	// i2c touchpad
+	touchpad := mustInputCapabilities(t, "b", "e520 10000 0 0 0 0", "2e0800000000003", "0", "5")
+	assert.Equal(t, InputClass{Touchpad: true}, ClassifyInput(touchpad, busI2C))
+
+	// touchscreen
+	touchscreen := mustInputCapabilities(t, "b", "400 0 0 0 0 0", "2608000 3", "0", "2")
+	assert.Equal(t, InputClass{Touchscreen: true}, ClassifyInput(touchscreen, 0x03))
+
+	// gamepad
+	pad := mustInputCapabilities(t, "20000b", "7fdb000000000000 0 0 0 0", "3003f", "0", "0")
+	assert.Equal(t, InputClass{Joystick: true}, ClassifyInput(pad, 0x03))
+
+	// lid switch
+	lid := mustInputCapabilities(t, "21", "0", "0", "0", "0")
+	assert.Equal(t, InputClass{Switch: true}, ClassifyInput(lid, 0x19))
+
+	// accelerometer
+	accel := mustInputCapabilities(t, "9", "0", "7", "0", "40")
+	assert.Equal(t, InputClass{Accelerometer: true}, ClassifyInput(accel, 0x18))
+}
+
+func TestInputDevices(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	e := ctx.NewEnumerate()
+	defer e.Free()
+
+	err := e.MatchSubsystem("input")
+	assert.Nil(t, err)
+
+	ds, err := e.Devices(nil)
+	assert.Nil(t, err)
+	defer FreeDevices(ds)
+
+	for _, d := range ds {
+		i, err := NewInputDevice(d)
+		if err != nil {
+			continue
+		}
+
+		ic, err := i.Classify()
+		assert.Nil(t, err)
+		node, _ := i.EventNode()
+		spew.Dump(i.Name(), node, ic)
+	}
+}
@@ -0,0 +1,221 @@
+package goudev
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	iommuGroupsPath = "/sys/kernel/iommu_groups"
+	vfioPciDriver   = "vfio-pci"
+
+	pciExtCapStart = 0x100
+	pciExtCapIDACS = 0x000d
+
+	// ACS control bits, include/uapi/linux/pci_regs.h
+	PciACSSourceValidation   = 0x0001
+	PciACSTranslationBlock   = 0x0002
+	PciACSRequestRedirect    = 0x0004
+	PciACSCompletionRedirect = 0x0008
+	PciACSUpstreamForward    = 0x0010
+
+	// the kernel's REQ_ACS_FLAGS needed to isolate devices below a port
+	pciACSIsolation = PciACSSourceValidation | PciACSRequestRedirect | PciACSCompletionRedirect | PciACSUpstreamForward
+)
+
+// pciExtCapabilities returns the offset of each PCIe extended capability in config space
+func pciExtCapabilities(config []byte) map[uint16]int {
+	caps := map[uint16]int{}
+
+	for off, n := pciExtCapStart, 0; off >= pciExtCapStart && off+4 <= len(config) && n < 480; n++ {
+		header := binary.LittleEndian.Uint32(config[off:])
+		if header == 0 || header == 0xffffffff {
+			break
+		}
+
+		id := uint16(header & 0xffff)
+		if _, ok := caps[id]; !ok {
+			caps[id] = off
+		}
+		off = int(header>>20) & 0xffc
+	}
+
+	return caps
+}
+
+// ACS returns the ACS capability and control registers; ok is false without
+// ACS or when the extended config space is not readable (needs root)
+func (p *PciDevice) ACS() (capability, control uint16, ok bool) {
+	config, err := os.ReadFile(filepath.Join(p.SysPath(), "config"))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	off, found := pciExtCapabilities(config)[pciExtCapIDACS]
+	if !found || off+8 > len(config) {
+		return 0, 0, false
+	}
+
+	return binary.LittleEndian.Uint16(config[off+4:]), binary.LittleEndian.Uint16(config[off+6:]), true
+}
+
+// ResetMethods returns the reset methods the kernel can use, like flr or bus;
+// older kernels without reset_method only report "reset" if any exists
+func (p *PciDevice) ResetMethods() []string {
+	if v := strings.TrimSpace(p.GetAttribute("reset_method")); v != "" {
+		return strings.Fields(v)
+	}
+	if _, err := os.Stat(filepath.Join(p.SysPath(), "reset")); err == nil {
+		return []string{"reset"}
+	}
+
+	return nil
+}
+
+// IommuGroupDevices returns all devices of the device's iommu group, including itself
+func (p *PciDevice) IommuGroupDevices() ([]*PciDevice, error) {
+	group, err := p.IommuGroup()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(iommuGroupsPath, strconv.Itoa(group), "devices"))
+	if err != nil {
+		return nil, err
+	}
+
+	ps := make([]*PciDevice, 0, len(entries))
+	for _, e := range entries {
+		d, err := Devices.FromName(p.context(), "pci", e.Name())
+		if err != nil {
+			continue // not a pci device
+		}
+		ps = append(ps, &PciDevice{d})
+	}
+
+	return ps, nil
+}
+
+type PassthroughGroupMember struct {
+	Address   string `json:"address"`
+	Class     string `json:"class"`
+	Driver    string `json:"driver"`
+	VfioBound bool   `json:"vfio_bound"`
+	IsBridge  bool   `json:"is_bridge"`
+}
+
+type PassthroughBridge struct {
+	Address string `json:"address"`
+	// false if the capability is missing or config space could not be read
+	HasACS     bool   `json:"has_acs"`
+	ACSControl uint16 `json:"acs_control"`
+	Isolated   bool   `json:"isolated"`
+}
+
+// PassthroughReport tells whether a PCI device can be passed through with VFIO
+type PassthroughReport struct {
+	Address      string                    `json:"address"`
+	Class        string                    `json:"class"`
+	Driver       string                    `json:"driver"`
+	IommuGroup   int                       `json:"iommu_group"`
+	Group        []*PassthroughGroupMember `json:"group"`
+	Bridges      []*PassthroughBridge      `json:"bridges"`
+	ResetMethods []string                  `json:"reset_methods"`
+	Ready        bool                      `json:"ready"`
+	Problems     []string                  `json:"problems,omitempty"`
+}
+
+func (p *PciDevice) PassthroughReport() (*PassthroughReport, error) {
+	r := &PassthroughReport{
+		Address:      p.SysName(),
+		Class:        p.ClassName(),
+		Driver:       p.CurrentDriver(),
+		IommuGroup:   -1,
+		ResetMethods: p.ResetMethods(),
+	}
+
+	if group, err := p.IommuGroup(); err == nil {
+		r.IommuGroup = group
+
+		members, err := p.IommuGroupDevices()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			gm := &PassthroughGroupMember{
+				Address:  m.SysName(),
+				Class:    m.ClassName(),
+				Driver:   m.CurrentDriver(),
+				IsBridge: m.IsBridge(),
+			}
+			gm.VfioBound = gm.Driver == vfioPciDriver
+			r.Group = append(r.Group, gm)
+
+			// bridges stay with their host driver, other members must be unused or with vfio
+			if !gm.IsBridge && !gm.VfioBound && gm.Driver != "" && gm.Driver != "pci-stub" {
+				r.Problems = append(r.Problems, fmt.Sprintf("%s in iommu group %d is bound to %s", gm.Address, group, gm.Driver))
+			}
+			m.Free()
+		}
+		sort.Slice(r.Group, func(i, j int) bool {
+			return r.Group[i].Address < r.Group[j].Address
+		})
+	} else {
+		r.Problems = append(r.Problems, "no iommu group, is the IOMMU enabled (intel_iommu=on/amd_iommu=on)?")
+	}
+
+	chain, err := p.BridgeChain()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range chain {
+		pb := &PassthroughBridge{Address: b.SysName()}
+		if _, ctrl, ok := b.ACS(); ok {
+			pb.HasACS = true
+			pb.ACSControl = ctrl
+			pb.Isolated = ctrl&pciACSIsolation == pciACSIsolation
+		}
+		r.Bridges = append(r.Bridges, pb)
+		b.Free()
+	}
+
+	if len(r.ResetMethods) == 0 {
+		r.Problems = append(r.Problems, "no reset method, the device may not work after reassignment")
+	}
+	r.Ready = len(r.Problems) == 0
+
+	return r, nil
+}
+
+// PassthroughReports reports every non-bridge PCI device of the host
+func (c *Context) PassthroughReports() ([]*PassthroughReport, error) {
+	e := c.NewEnumerate()
+	defer e.Free()
+
+	if err := e.MatchSubsystem("pci"); err != nil {
+		return nil, err
+	}
+	ds, err := e.Devices(func(td *Device) bool {
+		return !isPCIBridge(td)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer FreeDevices(ds)
+
+	rs := make([]*PassthroughReport, 0, len(ds))
+	for _, d := range ds {
+		r, err := (&PciDevice{d}).PassthroughReport()
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,37 @@
+package goudev
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPciExtCapabilities(t *testing.T) {
+	config := make([]byte, 4096)
+	// AER at 0x100 -> ACS at 0x148 -> end
+	binary.LittleEndian.PutUint32(config[0x100:], 0x0001|1<<16|0x148<<20)
+	binary.LittleEndian.PutUint32(config[0x148:], 0x000d|1<<16)
+	binary.LittleEndian.PutUint16(config[0x148+6:], 0x001d)
+
+	caps := pciExtCapabilities(config)
+	assert.Equal(t, map[uint16]int{0x0001: 0x100, 0x000d: 0x148}, caps)
+	assert.Equal(t, uint16(pciACSIsolation), binary.LittleEndian.Uint16(config[caps[pciExtCapIDACS]+6:])&pciACSIsolation)
+
+	// only the first 64 bytes are readable without root
+	assert.Empty(t, pciExtCapabilities(config[:64]))
+}
+
+func TestPassthroughReport(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "pci", "0000:65:00.0")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	r, err := (&PciDevice{d}).PassthroughReport()
+	assert.Nil(t, err)
+	spew.Dump(r)
+}
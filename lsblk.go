@@ -0,0 +1,228 @@
+package goudev
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	mountinfoPath = "/proc/self/mountinfo"
+	ramMajor      = 1 // skipped by lsblk unless -a
+)
+
+// NullString marshals "" as null, as lsblk --json does for empty columns
+type NullString string
+
+func (s NullString) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(string(s))
+}
+
+// BlockInfo is one row of `lsblk --json --bytes`, with the same field names
+type BlockInfo struct {
+	Name        string       `json:"name"`
+	KName       string       `json:"kname"`
+	Path        string       `json:"path"`
+	MajMin      string       `json:"maj:min"`
+	Rm          bool         `json:"rm"`
+	Size        uint64       `json:"size"`
+	Ro          bool         `json:"ro"`
+	Type        string       `json:"type"`
+	Rota        bool         `json:"rota"`
+	Tran        NullString   `json:"tran"`
+	Model       NullString   `json:"model"`
+	Serial      NullString   `json:"serial"`
+	WWN         NullString   `json:"wwn"`
+	PtType      NullString   `json:"pttype"`
+	FsType      NullString   `json:"fstype"`
+	UUID        NullString   `json:"uuid"`
+	Label       NullString   `json:"label"`
+	Mountpoints []NullString `json:"mountpoints"`
+	Children    []*BlockInfo `json:"children,omitempty"`
+}
+
+type mountEntry struct {
+	majMin string
+	source string
+	target string
+}
+
+// mountTable is /proc/self/mountinfo in mount order
+type mountTable []mountEntry
+
+// parseMountinfo reads the device number, source and mount point of every mount
+func parseMountinfo(r io.Reader) (mountTable, error) {
+	var t mountTable
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		fields := strings.Fields(s.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		e := mountEntry{majMin: fields[2], target: unescapeMountinfo(fields[4])}
+		// the optional fields end with "-", followed by the fs type and source
+		for i := 5; i < len(fields)-2; i++ {
+			if fields[i] == "-" {
+				e.source = unescapeMountinfo(fields[i+2])
+				break
+			}
+		}
+		t = append(t, e)
+	}
+
+	return t, s.Err()
+}
+
+// mountpoints returns the mounts of majMin. btrfs and the like report an anonymous
+// 0:NN device, those are matched by their source against nodes, the device node
+// and its links.
+func (t mountTable) mountpoints(majMin string, nodes ...string) []string {
+	var mps []string
+	for _, e := range t {
+		if e.majMin == majMin {
+			mps = append(mps, e.target)
+		}
+	}
+	if len(mps) > 0 {
+		return mps
+	}
+
+	for _, e := range t {
+		for _, n := range nodes {
+			if n != "" && e.source == n {
+				mps = append(mps, e.target)
+				break
+			}
+		}
+	}
+	return mps
+}
+
+// spaces and the like are octal escaped, e.g. \040
+func unescapeMountinfo(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+func newBlockInfo(n *BlockNode, mounts mountTable) *BlockInfo {
+	b := n.Device
+	devnum := b.DeviceNumber()
+	majMin := fmt.Sprintf("%d:%d", devnum.Major(), devnum.Minor())
+
+	info := &BlockInfo{
+		Name:   b.Name(),
+		KName:  b.SysName(),
+		Path:   b.DeviceNode(),
+		MajMin: majMin,
+		Rm:     b.Removable(),
+		Size:   b.Size(),
+		Ro:     b.ReadOnly(),
+		Type:   n.Layer,
+		Rota:   b.Rotational(),
+		Tran:   NullString(b.Transport()),
+		Model:  NullString(b.Model()),
+		Serial: NullString(b.Serial()),
+		WWN:    NullString(b.WWN()),
+		PtType: NullString(b.PartitionTableType()),
+		FsType: NullString(b.FilesystemType()),
+		UUID:   NullString(b.FilesystemUUID()),
+		Label:  NullString(b.FilesystemLabel()),
+	}
+	if n.Layer != BlockLayerDisk && n.Layer != BlockLayerRom {
+		// like lsblk, only whole disks carry the hardware columns
+		info.Tran, info.Model, info.Serial = "", "", ""
+	}
+	if strings.HasPrefix(b.SysName(), "dm-") {
+		info.Path = "/dev/mapper/" + info.Name
+	}
+
+	nodes := []string{b.DeviceNode(), info.Path}
+	for link := range b.DeviceLinks() {
+		nodes = append(nodes, link)
+	}
+	for _, mp := range mounts.mountpoints(majMin, nodes...) {
+		info.Mountpoints = append(info.Mountpoints, NullString(mp))
+	}
+	if len(info.Mountpoints) == 0 {
+		info.Mountpoints = []NullString{""}
+	}
+
+	for _, c := range n.Children {
+		info.Children = append(info.Children, newBlockInfo(c, mounts))
+	}
+
+	return info
+}
+
+// BlockInventory lists block devices as a tree like lsblk: top level devices without
+// slaves, with their partitions and holders as children. Empty and ram devices are skipped.
+func (c *Context) BlockInventory() ([]*BlockInfo, error) {
+	var mounts mountTable
+	if f, err := os.Open(mountinfoPath); err == nil {
+		mounts, err = parseMountinfo(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e := c.NewEnumerate()
+	defer e.Free()
+
+	if err := e.MatchSubsystem("block"); err != nil {
+		return nil, err
+	}
+	ds, err := e.Devices(WithFilterBlockDevtype("disk"))
+	if err != nil {
+		return nil, err
+	}
+	defer FreeDevices(ds)
+
+	infos := make([]*BlockInfo, 0, len(ds))
+	for _, d := range ds {
+		b := &BlockDevice{d}
+		if b.DeviceNumber().Major() == ramMajor || b.Size() == 0 {
+			continue
+		}
+		if ss, err := b.Slaves(); err != nil {
+			return nil, err
+		} else if len(ss) > 0 {
+			FreeBlockDevices(ss)
+			continue
+		}
+
+		n, err := b.HolderTree()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, newBlockInfo(n, mounts))
+		n.Free()
+	}
+
+	return infos, nil
+}
@@ -0,0 +1,62 @@
+package goudev
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMountinfo(t *testing.T) {
+	m, err := parseMountinfo(strings.NewReader(`22 1 259:2 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p2 rw
+23 22 259:1 / /boot/efi rw,relatime shared:2 - vfat /dev/nvme0n1p1 rw
+24 22 0:21 / /proc rw,nosuid shared:3 - proc proc rw
+25 22 259:2 /srv /mnt/my\040data rw,relatime shared:1 - ext4 /dev/nvme0n1p2 rw
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/", "/mnt/my data"}, m.mountpoints("259:2"))
+	assert.Equal(t, []string{"/boot/efi"}, m.mountpoints("259:1"))
+	assert.Nil(t, m.mountpoints("8:0", "/dev/sda"))
+}
+
+func TestParseMountinfoBtrfs(t *testing.T) {
+	// btrfs mounts carry an anonymous device number, not the one of the block device
+	m, err := parseMountinfo(strings.NewReader(`29 1 0:26 /@ / rw,relatime shared:1 - btrfs /dev/nvme0n1p3 rw,ssd,subvol=/@
+30 29 0:26 /@home /home rw,relatime shared:2 - btrfs /dev/nvme0n1p3 rw,ssd,subvol=/@home
+31 29 0:27 / /data rw,relatime shared:3 - btrfs /dev/disk/by-label/data rw
+32 29 259:1 / /boot/efi rw,relatime shared:4 - vfat /dev/nvme0n1p1 rw
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/", "/home"}, m.mountpoints("259:3", "/dev/nvme0n1p3"))
+	assert.Equal(t, []string{"/data"}, m.mountpoints("8:1", "/dev/sda1", "/dev/disk/by-label/data"))
+	// the device number wins over the source
+	assert.Equal(t, []string{"/boot/efi"}, m.mountpoints("259:1", "/dev/nvme0n1p3"))
+}
+
+func TestBlockInfoJSON(t *testing.T) {
+	data, err := json.Marshal(&BlockInfo{
+		Name:        "nvme0n1",
+		MajMin:      "259:0",
+		Type:        BlockLayerDisk,
+		Model:       "Samsung SSD",
+		Mountpoints: []NullString{""},
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"maj:min":"259:0"`)
+	assert.Contains(t, string(data), `"model":"Samsung SSD"`)
+	assert.Contains(t, string(data), `"serial":null`)
+	assert.Contains(t, string(data), `"mountpoints":[null]`)
+	assert.NotContains(t, string(data), `"children"`)
+}
+
+func TestBlockInventory(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	infos, err := ctx.BlockInventory()
+	assert.Nil(t, err)
+
+	data, _ := json.MarshalIndent(infos, "", "  ")
+	t.Log(string(data))
+}
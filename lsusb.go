@@ -0,0 +1,150 @@
+package goudev
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type UsbInterfaceInfo struct {
+	Number    int    `json:"number"`
+	Class     string `json:"class"`
+	ClassName string `json:"class_name"`
+	SubClass  string `json:"subclass"`
+	Protocol  string `json:"protocol"`
+	Driver    string `json:"driver"`
+}
+
+// UsbInfo is one usb_device of the USB topology, root hubs at the top
+type UsbInfo struct {
+	SysName    string              `json:"sysname"`
+	Bus        int                 `json:"bus"`
+	Device     int                 `json:"device"`
+	Port       int                 `json:"port"`
+	PortPath   string              `json:"port_path"`
+	VendorID   string              `json:"vendor_id"`
+	ProductID  string              `json:"product_id"`
+	Vendor     string              `json:"vendor"`
+	Product    string              `json:"product"`
+	Serial     string              `json:"serial,omitempty"`
+	Speed      float64             `json:"speed"` // Mbit/s
+	Class      string              `json:"class"`
+	Driver     string              `json:"driver"` // host controller driver for root hubs
+	MaxChild   int                 `json:"max_child,omitempty"`
+	Interfaces []*UsbInterfaceInfo `json:"interfaces"`
+	Children   []*UsbInfo          `json:"children,omitempty"`
+}
+
+// prefer the hwdb names like lsusb, fall back to the device strings
+func usbName(u *UsbDevice, property, attribute string) string {
+	if v := u.Get(property); v != "" {
+		return v
+	}
+
+	return strings.TrimSpace(u.GetAttribute(attribute))
+}
+
+func newUsbInfo(u *UsbDevice) (*UsbInfo, error) {
+	info := &UsbInfo{
+		SysName:   u.SysName(),
+		Bus:       u.BusNum(),
+		Device:    u.DevNum(),
+		PortPath:  u.PortPath(),
+		VendorID:  fmt.Sprintf("%04x", u.VendorID()),
+		ProductID: fmt.Sprintf("%04x", u.ProductID()),
+		Vendor:    usbName(u, "ID_VENDOR_FROM_DATABASE", "manufacturer"),
+		Product:   usbName(u, "ID_MODEL_FROM_DATABASE", "product"),
+		Serial:    u.Serial(),
+		Speed:     u.Speed(),
+		Class:     UsbClassName(u.Class()),
+		Driver:    u.Driver(),
+	}
+	if i := strings.LastIndexByte(info.PortPath, '.'); i >= 0 {
+		info.Port, _ = strconv.Atoi(info.PortPath[i+1:])
+	} else {
+		info.Port, _ = strconv.Atoi(info.PortPath)
+	}
+	if u.IsRootHub() {
+		info.Class = "root_hub"
+		info.Port = 1
+		info.MaxChild, _ = u.attributeInt("maxchild")
+
+		// like lsusb -t, show the HCD (e.g. xhci_hcd) instead of the generic "usb" driver
+		if hcd, err := u.Parent(); err == nil {
+			info.Driver = hcd.CurrentDriver()
+			hcd.Free()
+		}
+	}
+
+	is, err := u.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, i := range is {
+			i.Free()
+		}
+	}()
+	for _, i := range is {
+		info.Interfaces = append(info.Interfaces, &UsbInterfaceInfo{
+			Number:    int(i.InterfaceNumber()),
+			Class:     fmt.Sprintf("%02x", i.Class()),
+			ClassName: UsbClassName(i.Class()),
+			SubClass:  fmt.Sprintf("%02x", i.SubClass()),
+			Protocol:  fmt.Sprintf("%02x", i.Protocol()),
+			Driver:    i.Driver(),
+		})
+	}
+	sort.Slice(info.Interfaces, func(i, j int) bool {
+		return info.Interfaces[i].Number < info.Interfaces[j].Number
+	})
+
+	return info, nil
+}
+
+// UsbInventory returns the USB topology: one tree per root hub, ordered by bus and port
+func (c *Context) UsbInventory() ([]*UsbInfo, error) {
+	ds, err := c.subsystemDevices("usb", WithFilterDevtype("usb_device"))
+	if err != nil {
+		return nil, err
+	}
+	defer FreeDevices(ds)
+
+	infos := make(map[string]*UsbInfo, len(ds))
+	for _, d := range ds {
+		info, err := newUsbInfo(&UsbDevice{d})
+		if err != nil {
+			return nil, err
+		}
+		infos[d.SysPath()] = info
+	}
+
+	roots := make([]*UsbInfo, 0)
+	for _, d := range ds {
+		info := infos[d.SysPath()]
+		// a hub's children sit directly below it in sysfs
+		if p, ok := infos[filepath.Dir(d.SysPath())]; ok {
+			p.Children = append(p.Children, info)
+		} else {
+			roots = append(roots, info)
+		}
+	}
+
+	for _, info := range infos {
+		sort.Slice(info.Children, func(i, j int) bool {
+			return info.Children[i].Port < info.Children[j].Port
+		})
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Bus < roots[j].Bus
+	})
+
+	return roots, nil
+}
+
+// FormatUsbSpeed formats Mbit/s like lsusb -t: 1.5M, 12M, 480M, 5000M
+func FormatUsbSpeed(speed float64) string {
+	return strconv.FormatFloat(speed, 'f', -1, 64) + "M"
+}
@@ -0,0 +1,30 @@
+package goudev
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatUsbSpeed(t *testing.T) {
+	assert.Equal(t, "1.5M", FormatUsbSpeed(1.5))
+	assert.Equal(t, "480M", FormatUsbSpeed(480))
+	assert.Equal(t, "5000M", FormatUsbSpeed(5000))
+}
+
+func TestUsbInventory(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	infos, err := ctx.UsbInventory()
+	assert.Nil(t, err)
+
+	for _, info := range infos {
+		assert.Equal(t, "root_hub", info.Class)
+		assert.NotEqual(t, "usb", info.Driver)
+	}
+
+	data, _ := json.MarshalIndent(infos, "", "  ")
+	t.Log(string(data))
+}
@@ -0,0 +1,140 @@
+package goudev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	memoryBlockSizePath = "/sys/devices/system/memory/block_size_bytes"
+)
+
+// memory block states, online_kernel/online_movable also select the zone
+const (
+	MemoryOnline        = "online"
+	MemoryOffline       = "offline"
+	MemoryGoingOffline  = "going-offline"
+	MemoryOnlineKernel  = "online_kernel"
+	MemoryOnlineMovable = "online_movable"
+)
+
+var (
+	memoryNameRegexp = regexp.MustCompile(`^memory(\d+)$`)
+
+	ErrNotMemoryBlock     = errors.New("udev: device is not a memory block")
+	ErrInvalidMemoryState = errors.New("udev: memory state must be online, offline, online_kernel or online_movable")
+)
+
+// MemoryBlockSize returns the size of every memory block in bytes
+func MemoryBlockSize() (uint64, error) {
+	data, err := os.ReadFile(memoryBlockSizePath)
+	if err != nil {
+		return 0, err
+	}
+
+	// hex without 0x prefix
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 16, 64)
+}
+
+// MemoryBlock is a typed view of a memory subsystem Device, e.g. memory32
+type MemoryBlock struct {
+	*Device
+}
+
+func NewMemoryBlock(d *Device) (*MemoryBlock, error) {
+	if d.Subsystem() != "memory" || !memoryNameRegexp.MatchString(d.SysName()) {
+		return nil, ErrNotMemoryBlock
+	}
+
+	return &MemoryBlock{d}, nil
+}
+
+func (m *MemoryBlock) Index() int {
+	v, _ := strconv.Atoi(m.SysNumber())
+	return v
+}
+
+// PhysAddress returns the physical start address of the block
+func (m *MemoryBlock) PhysAddress() (uint64, error) {
+	size, err := MemoryBlockSize()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(m.Index()) * size, nil
+}
+
+// State is read from sysfs on every call
+func (m *MemoryBlock) State() string {
+	return m.readAttribute("state")
+}
+
+func (m *MemoryBlock) Online() bool {
+	return m.State() == MemoryOnline
+}
+
+// SetState writes one of the Memory* states, e.g. MemoryOnlineMovable to online
+// the block into ZONE_MOVABLE. MemoryGoingOffline is only ever read.
+func (m *MemoryBlock) SetState(state string) error {
+	switch state {
+	case MemoryOnline, MemoryOffline, MemoryOnlineKernel, MemoryOnlineMovable:
+	default:
+		return fmt.Errorf("%w, got %q", ErrInvalidMemoryState, state)
+	}
+
+	return m.SetAttribute("state", state)
+}
+
+// SetOnline onlines into the kernel default zone or offlines the block
+func (m *MemoryBlock) SetOnline(online bool) error {
+	if online {
+		return m.SetState(MemoryOnline)
+	}
+	return m.SetState(MemoryOffline)
+}
+
+// ValidZones returns the zones the block can be onlined to, or the zone of an online block
+func (m *MemoryBlock) ValidZones() []string {
+	zs := strings.Fields(m.readAttribute("valid_zones"))
+	if len(zs) == 1 && zs[0] == "none" {
+		return nil
+	}
+	return zs
+}
+
+// Removable reports whether the block may be offlined. Since 5.8 the kernel
+// always reports 1 and offlining is the only way to find out.
+func (m *MemoryBlock) Removable() bool {
+	return m.readAttribute("removable") == "1"
+}
+
+func (m *MemoryBlock) Node() int {
+	return nodeOf(m.Device)
+}
+
+// MemoryBlocks returns all memory blocks ordered by index
+func (c *Context) MemoryBlocks() ([]*MemoryBlock, error) {
+	ds, err := c.subsystemDevices("memory", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ms := make([]*MemoryBlock, 0, len(ds))
+	for _, d := range ds {
+		if m, err := NewMemoryBlock(d); err == nil {
+			ms = append(ms, m)
+			continue
+		}
+		d.Free()
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Index() < ms[j].Index()
+	})
+
+	return ms, nil
+}
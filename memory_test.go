@@ -0,0 +1,36 @@
+package goudev
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBlocks(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	size, err := MemoryBlockSize()
+	assert.Nil(t, err)
+
+	ms, err := ctx.MemoryBlocks()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, ms)
+
+	for _, m := range ms {
+		addr, err := m.PhysAddress()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(m.Index())*size, addr)
+		spew.Dump(m.SysName(), m.State(), m.ValidZones(), m.Removable(), m.Node())
+		m.Free()
+	}
+}
+
+func TestMemoryBlockSetStateInvalid(t *testing.T) {
+	m := &MemoryBlock{}
+	for _, state := range []string{MemoryGoingOffline, "", "online_movable\n", "on"} {
+		assert.True(t, errors.Is(m.SetState(state), ErrInvalidMemoryState), state)
+	}
+}
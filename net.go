@@ -0,0 +1,165 @@
+package goudev
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// net interface kinds, see NetDevice.Kind
+const (
+	NetKindPhysical = "physical"
+	NetKindVirtual  = "virtual"
+	NetKindLoopback = "loopback"
+	NetKindBridge   = "bridge"
+	NetKindBond     = "bond"
+	NetKindVLAN     = "vlan"
+)
+
+const (
+	arphrdLoopback = "772" // include/uapi/linux/if_arp.h
+)
+
+var (
+	ErrNotNetDevice = errors.New("udev: device is not in the net subsystem")
+)
+
+// NetDevice is a typed view of a net subsystem Device
+type NetDevice struct {
+	*Device
+}
+
+func NewNetDevice(d *Device) (*NetDevice, error) {
+	if d.Subsystem() != "net" {
+		return nil, ErrNotNetDevice
+	}
+
+	return &NetDevice{d}, nil
+}
+
+func (n *NetDevice) Index() int {
+	i, _ := n.attributeInt("ifindex")
+	return i
+}
+
+func (n *NetDevice) InterfaceName() string {
+	if v := n.Get("INTERFACE"); v != "" {
+		return v
+	}
+
+	return n.SysName()
+}
+
+func (n *NetDevice) HardwareAddr() (net.HardwareAddr, error) {
+	return net.ParseMAC(strings.TrimSpace(n.GetAttribute("address")))
+}
+
+// OperState is up, down, dormant, lowerlayerdown, notpresent, testing or unknown
+func (n *NetDevice) OperState() string {
+	return strings.TrimSpace(n.GetAttribute("operstate"))
+}
+
+// Carrier is false when the interface is down, the kernel refuses to read it then
+func (n *NetDevice) Carrier() bool {
+	return n.attributeBool("carrier")
+}
+
+func (n *NetDevice) MTU() int {
+	i, _ := n.attributeInt("mtu")
+	return i
+}
+
+// Speed in Mbit/s, -1 if unknown
+func (n *NetDevice) Speed() int {
+	i, err := n.attributeInt("speed")
+	if err != nil {
+		return -1
+	}
+
+	return i
+}
+
+// Duplex is full, half or unknown
+func (n *NetDevice) Duplex() string {
+	if v := strings.TrimSpace(n.GetAttribute("duplex")); v != "" {
+		return v
+	}
+
+	return "unknown"
+}
+
+// Driver returns the driver of the underlying device, net devices have none themselves
+func (n *NetDevice) Driver() string {
+	if v := n.Get("ID_NET_DRIVER"); v != "" {
+		return v
+	}
+
+	p, err := os.Readlink(filepath.Join(n.SysPath(), "device", "driver"))
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(p)
+}
+
+// BusPath is the persistent ID_PATH, e.g. pci-0000:03:00.1
+func (n *NetDevice) BusPath() string {
+	return n.Get("ID_PATH")
+}
+
+// NetNames are the predictable interface names set by the net_id builtin
+type NetNames struct {
+	Onboard string // ID_NET_NAME_ONBOARD, eno1
+	Slot    string // ID_NET_NAME_SLOT, ens1
+	Path    string // ID_NET_NAME_PATH, enp3s0f1
+	MAC     string // ID_NET_NAME_MAC, enx78e7d1ea46da
+}
+
+func (n *NetDevice) PredictableNames() NetNames {
+	return NetNames{
+		Onboard: n.Get("ID_NET_NAME_ONBOARD"),
+		Slot:    n.Get("ID_NET_NAME_SLOT"),
+		Path:    n.Get("ID_NET_NAME_PATH"),
+		MAC:     n.Get("ID_NET_NAME_MAC"),
+	}
+}
+
+// IsPhysical reports whether the interface is backed by a device, e.g. a PCI or USB NIC
+func (n *NetDevice) IsPhysical() bool {
+	_, err := os.Stat(filepath.Join(n.SysPath(), "device"))
+	return err == nil
+}
+
+// Kind returns one of NetKind*
+func (n *NetDevice) Kind() string {
+	switch {
+	case strings.TrimSpace(n.GetAttribute("type")) == arphrdLoopback:
+		return NetKindLoopback
+	case n.hasDir("bridge"):
+		return NetKindBridge
+	case n.hasDir("bonding"):
+		return NetKindBond
+	case n.DeviceType() == "vlan":
+		return NetKindVLAN
+	case n.IsPhysical():
+		return NetKindPhysical
+	}
+
+	return NetKindVirtual
+}
+
+func (n *NetDevice) hasDir(name string) bool {
+	fi, err := os.Stat(filepath.Join(n.SysPath(), name))
+	return err == nil && fi.IsDir()
+}
+
+// BusDevice returns the nearest PCI function or USB device the interface belongs to
+func (n *NetDevice) BusDevice() (*Device, error) {
+	if d, err := n.FindParent("usb", "usb_device"); err == nil {
+		return d, nil
+	}
+
+	return n.FindParent("pci")
+}
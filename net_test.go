@@ -0,0 +1,41 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetDevice(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	e := ctx.NewEnumerate()
+	defer e.Free()
+
+	err := e.MatchSubsystem("net")
+	assert.Nil(t, err)
+
+	ds, err := e.Devices(nil)
+	assert.Nil(t, err)
+	defer FreeDevices(ds)
+
+	for _, d := range ds {
+		n, err := NewNetDevice(d)
+		assert.Nil(t, err)
+
+		mac, _ := n.HardwareAddr()
+		spew.Dump(n.InterfaceName(), n.Index(), mac.String(), n.OperState(), n.Carrier(), n.MTU(), n.Speed(), n.Duplex())
+		spew.Dump(n.Kind(), n.Driver(), n.BusPath(), n.PredictableNames())
+
+		if n.Kind() == NetKindLoopback {
+			assert.False(t, n.IsPhysical())
+		}
+
+		if p, err := n.BusDevice(); err == nil {
+			spew.Dump(p.String())
+			p.Free()
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package goudev
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// naming scheme features, a subset of systemd src/shared/netif-naming-scheme.h
+const (
+	namingSRIOVV                  = 1 << iota // VF names use the PF name plus "v<n>"
+	namingNPARARI                             // ARI devices fold the slot into the function number
+	namingInfiniband                          // "ib" prefix for infiniband
+	namingBridgeNoSlot                        // hotplug slots of bridges are not used
+	naming16BitIndex                          // onboard index up to 65535 instead of 16383
+	namingBridgeMultifunctionSlot             // bridge slots are used again for multifunction devices
+)
+
+const (
+	arphrdEther      = 1
+	arphrdSlip       = 256
+	arphrdInfiniband = 32
+
+	netAddrPerm = 0 // addr_assign_type
+)
+
+var (
+	ErrNetNamingUnsupported    = errors.New("udev: interface is not named by net_id")
+	ErrUnknownNamingSchemeTmpl = "udev: unknown naming scheme %q"
+)
+
+// NamingScheme selects the net_id naming behaviour, like net.naming-scheme= on the kernel cmdline
+type NamingScheme struct {
+	Name  string
+	flags int
+}
+
+func (s *NamingScheme) has(flag int) bool {
+	return s.flags&flag != 0
+}
+
+const (
+	namingV239 = namingSRIOVV | namingNPARARI
+	namingV240 = namingV239 | namingInfiniband
+	namingV247 = namingV240 | namingBridgeNoSlot
+	namingV249 = namingV247 | naming16BitIndex
+	namingV251 = namingV249 | namingBridgeMultifunctionSlot
+	namingV255 = namingV251 &^ namingBridgeMultifunctionSlot
+)
+
+// https://www.freedesktop.org/software/systemd/man/systemd.net-naming-scheme.html
+//
+// Only the changes affecting the names ComputeNames produces are modelled. Not
+// supported: devicetree aliases (v252), path names of USB devices without a PCI
+// parent (v253), SR-IOV representor names (v254) and s390 function_id slots (v249);
+// such interfaces get no or different names than udevd assigns.
+var namingSchemes = []*NamingScheme{
+	{"v238", 0},
+	{"v239", namingV239},
+	{"v240", namingV240},
+	{"v241", namingV240},
+	{"v243", namingV240},
+	{"v245", namingV240},
+	{"v247", namingV247},
+	{"v249", namingV249},
+	{"v250", namingV249},
+	{"v251", namingV251},
+	{"v252", namingV251},
+	{"v253", namingV251},
+	{"v254", namingV251},
+	{"v255", namingV255},
+}
+
+// LookupNamingScheme returns a scheme by name, "" and "latest" select the newest one
+func LookupNamingScheme(name string) (*NamingScheme, error) {
+	if name == "" || name == "latest" {
+		return namingSchemes[len(namingSchemes)-1], nil
+	}
+
+	for _, s := range namingSchemes {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf(ErrUnknownNamingSchemeTmpl, name)
+}
+
+// devPortOrID returns dev_port; IPoIB interfaces of old kernels report the port in
+// dev_id (hex) and leave dev_port 0, which net_id only accepts for infiniband
+func devPortOrID(devPort, devID string, infiniband bool) uint64 {
+	n, _ := parseUint64(devPort)
+	if n == 0 && infiniband {
+		if id, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(devID), "0x"), 16, 64); err == nil {
+			return id
+		}
+	}
+
+	return n
+}
+
+// port suffix of slot, path and onboard names: n<phys_port_name> or d<dev_port>
+func portSuffix(physPortName string, devPort uint64) string {
+	if physPortName != "" {
+		return "n" + physPortName
+	}
+	if devPort > 0 {
+		return fmt.Sprintf("d%d", devPort)
+	}
+
+	return ""
+}
+
+// pciPathName composes the p<bus>s<slot>[f<func>] part, with a P<domain> prefix for domain > 0
+func pciPathName(domain, bus, slot, fn uint64, multifunction bool) string {
+	s := ""
+	if domain > 0 {
+		s = fmt.Sprintf("P%d", domain)
+	}
+	s += fmt.Sprintf("p%ds%d", bus, slot)
+	if fn > 0 || multifunction {
+		s += fmt.Sprintf("f%d", fn)
+	}
+
+	return s
+}
+
+// usbPortName turns a usb_interface sysname like "1-1.2:1.0" into "u1u2",
+// leaving out the common configuration 1 and interface 0
+func usbPortName(sysname string) (string, error) {
+	i := strings.IndexByte(sysname, '-')
+	j := strings.IndexByte(sysname, ':')
+	if i < 0 || j < i {
+		return "", ErrNetNamingUnsupported
+	}
+	config, intf, ok := strings.Cut(sysname[j+1:], ".")
+	if !ok {
+		return "", ErrNetNamingUnsupported
+	}
+
+	s := "u" + strings.ReplaceAll(sysname[i+1:j], ".", "u")
+	if config != "1" {
+		s += "c" + config
+	}
+	if intf != "0" {
+		s += "i" + intf
+	}
+
+	return s, nil
+}
+
+// ARI devices have up to 256 functions, the slot bits are the upper 5 bits of the
+// function number. net_id keeps the slot in the name as well: p<bus>s<slot>f<slot*8+fn>.
+func ariFunction(slot, fn uint64) uint64 {
+	return slot*8 + fn
+}
+
+func parsePCIAddress(sysname string) (domain, bus, slot, fn uint64, err error) {
+	// 0000:65:00.0
+	var n int
+	n, err = fmt.Sscanf(sysname, "%x:%x:%x.%d", &domain, &bus, &slot, &fn)
+	if err == nil && n != 4 {
+		err = ErrNetNamingUnsupported
+	}
+	return
+}
+
+func (d *Device) pciClass() uint64 {
+	c, _ := d.attributeUint64("class")
+	return c
+}
+
+func isPCIBridge(d *Device) bool {
+	return d.pciClass()>>8 == 0x0604
+}
+
+// header type bit 7 of the config space
+func isPCIMultifunction(d *Device) bool {
+	f, err := os.Open(filepath.Join(d.SysPath(), "config"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	b := make([]byte, 1)
+	if _, err = f.ReadAt(b, 0x0e); err != nil {
+		return false
+	}
+
+	return b[0]&0x80 != 0
+}
+
+// virtfnIndex returns the VF index of vf below its PF
+func virtfnIndex(pf, vf *Device) (int, error) {
+	links, err := filepath.Glob(filepath.Join(pf.SysPath(), "virtfn*"))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, l := range links {
+		if p, err := filepath.EvalSymlinks(l); err == nil && p == vf.SysPath() {
+			return strconv.Atoi(strings.TrimPrefix(filepath.Base(l), "virtfn"))
+		}
+	}
+
+	return 0, ErrNotVirtualFunction
+}
+
+// pciSlotNumber searches /sys/bus/pci/slots for the hotplug slot of d or its bridges
+func pciSlotNumber(scheme *NamingScheme, d *Device) uint64 {
+	dirs, err := os.ReadDir("/sys/bus/pci/slots")
+	if err != nil {
+		return 0
+	}
+
+	multifunction := isPCIMultifunction(d)
+	cur := d
+	for {
+		sysname := cur.SysName()
+		for _, dir := range dirs {
+			n, err := strconv.ParseUint(dir.Name(), 10, 64)
+			if err != nil || n < 1 {
+				continue
+			}
+
+			addr, err := os.ReadFile(filepath.Join("/sys/bus/pci/slots", dir.Name(), "address"))
+			if err != nil {
+				continue
+			}
+			// the slot address has no function: 0000:05:00
+			if a := strings.TrimSpace(string(addr)); a != "" && (strings.HasPrefix(sysname, a+".") || sysname == a) {
+				bridge := isPCIBridge(cur)
+				if cur != d {
+					cur.Free()
+				}
+				if !bridgeSlotAllowed(scheme, bridge, multifunction) {
+					return 0
+				}
+				return n
+			}
+		}
+
+		p, err := cur.FindParent("pci")
+		if cur != d {
+			cur.Free()
+		}
+		if err != nil {
+			return 0
+		}
+		cur = p
+	}
+}
+
+// bridgeSlotAllowed decides whether the hotplug slot found on a device may name it.
+// A bridge slot is shared by everything behind it: v247 stopped using it, v251 used it
+// again for multifunction devices and v255 reverted that.
+func bridgeSlotAllowed(scheme *NamingScheme, bridge, multifunction bool) bool {
+	if !bridge || !scheme.has(namingBridgeNoSlot) {
+		return true
+	}
+
+	return scheme.has(namingBridgeMultifunctionSlot) && multifunction
+}
+
+type netIDInfo struct {
+	arphrd       int
+	prefix       string
+	physPortName string
+	devPort      uint64
+}
+
+func (n *NetDevice) netIDInfo(scheme *NamingScheme) (*netIDInfo, error) {
+	ifindex, _ := n.attributeInt("ifindex")
+	iflink, err := n.attributeInt("iflink")
+	// stacked devices like VLANs are skipped
+	if err == nil && iflink != ifindex {
+		return nil, ErrNetNamingUnsupported
+	}
+
+	t, _ := n.attributeInt("type")
+	info := &netIDInfo{arphrd: t}
+	switch t {
+	case arphrdEther:
+		info.prefix = "en"
+	case arphrdInfiniband:
+		if !scheme.has(namingInfiniband) {
+			return nil, ErrNetNamingUnsupported
+		}
+		info.prefix = "ib"
+	case arphrdSlip:
+		info.prefix = "sl"
+	default:
+		return nil, ErrNetNamingUnsupported
+	}
+	switch n.DeviceType() {
+	case "wlan":
+		info.prefix = "wl"
+	case "wwan":
+		info.prefix = "ww"
+	}
+
+	info.physPortName = strings.TrimSpace(n.GetAttribute("phys_port_name"))
+	info.devPort = devPortOrID(n.GetAttribute("dev_port"), n.GetAttribute("dev_id"),
+		t == arphrdInfiniband && scheme.has(namingInfiniband))
+
+	return info, nil
+}
+
+// macName is the ID_NET_NAME_MAC value, like enx001122334455. net_id only names
+// ETH_ALEN addresses and never infiniband ones.
+func macName(prefix string, arphrd int, mac net.HardwareAddr) string {
+	if len(mac) != 6 || arphrd == arphrdInfiniband {
+		return ""
+	}
+	return prefix + "x" + strings.ReplaceAll(mac.String(), ":", "")
+}
+
+// ComputeNames computes the ID_NET_NAME_* values the net_id builtin would assign with
+// scheme, without udevd. Onboard, slot, path (PCI and USB) and MAC names are supported.
+func (n *NetDevice) ComputeNames(scheme *NamingScheme) (NetNames, error) {
+	var names NetNames
+
+	info, err := n.netIDInfo(scheme)
+	if err != nil {
+		return names, err
+	}
+
+	if t, _ := n.attributeInt("addr_assign_type"); t == netAddrPerm {
+		if mac, err := n.HardwareAddr(); err == nil {
+			names.MAC = macName(info.prefix, info.arphrd, mac)
+		}
+	}
+
+	pci, err := n.FindParent("pci")
+	if err != nil {
+		return names, nil
+	}
+	defer pci.Free()
+
+	// a VF is named after its PF plus "v<n>"
+	naming, vfSuffix := pci, ""
+	if scheme.has(namingSRIOVV) && pci.IsVirtualFunction() {
+		if pf, err := pci.PhysicalFunction(); err == nil {
+			defer pf.Free()
+			if i, err := virtfnIndex(pf, pci); err == nil {
+				naming, vfSuffix = pf, fmt.Sprintf("v%d", i)
+			}
+		}
+	}
+
+	domain, bus, slot, fn, err := parsePCIAddress(naming.SysName())
+	if err != nil {
+		return names, nil
+	}
+	if scheme.has(namingNPARARI) && naming.attributeBool("ari_enabled") {
+		fn = ariFunction(slot, fn)
+	}
+	multifunction := isPCIMultifunction(naming)
+	port := portSuffix(info.physPortName, info.devPort)
+
+	usb := ""
+	if intf, err := n.FindParent("usb", "usb_interface"); err == nil {
+		usb, err = usbPortName(intf.SysName())
+		intf.Free()
+		if err != nil {
+			return names, nil
+		}
+	}
+
+	names.Path = info.prefix + pciPathName(domain, bus, slot, fn, multifunction) + port + vfSuffix + usb
+
+	if hotplug := pciSlotNumber(scheme, naming); hotplug > 0 {
+		s := info.prefix
+		if domain > 0 {
+			s += fmt.Sprintf("P%d", domain)
+		}
+		s += fmt.Sprintf("s%d", hotplug)
+		if fn > 0 || multifunction {
+			s += fmt.Sprintf("f%d", fn)
+		}
+		names.Slot = s + port + vfSuffix + usb
+	}
+
+	if vfSuffix == "" && usb == "" {
+		names.Onboard = n.onboardName(scheme, naming, info, port)
+	}
+
+	return names, nil
+}
+
+func (n *NetDevice) onboardName(scheme *NamingScheme, pci *Device, info *netIDInfo, port string) string {
+	// ACPI _DSM first, then SMBIOS type 41
+	idx, err := pci.attributeUint64("acpi_index")
+	if err != nil {
+		idx, err = pci.attributeUint64("index")
+	}
+	if err != nil || idx == 0 {
+		return ""
+	}
+
+	// firmware reports rubbish like 2^24-1 for some embedded NICs
+	max := uint64(16*1024 - 1)
+	if scheme.has(naming16BitIndex) {
+		max = 64*1024 - 1
+	}
+	if idx > max {
+		return ""
+	}
+
+	return fmt.Sprintf("%so%d", info.prefix, idx) + port
+}
+
+// Mismatches compares computed names with the ones udevd set, e.g.
+// dev.ComputeNames(scheme) against dev.PredictableNames()
+func (a NetNames) Mismatches(b NetNames) []string {
+	var ms []string
+	for _, c := range []struct {
+		key  string
+		a, b string
+	}{
+		{"ID_NET_NAME_ONBOARD", a.Onboard, b.Onboard},
+		{"ID_NET_NAME_SLOT", a.Slot, b.Slot},
+		{"ID_NET_NAME_PATH", a.Path, b.Path},
+		{"ID_NET_NAME_MAC", a.MAC, b.MAC},
+	} {
+		if c.a != c.b {
+			ms = append(ms, fmt.Sprintf("%s: %q != %q", c.key, c.a, c.b))
+		}
+	}
+
+	return ms
+}
@@ -0,0 +1,142 @@
+package goudev
+
+import (
+	"net"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetIDNameParts(t *testing.T) {
+	assert.Equal(t, "p3s0", pciPathName(0, 3, 0, 0, false))
+	assert.Equal(t, "p3s0f1", pciPathName(0, 3, 0, 1, false))
+	assert.Equal(t, "p101s0f0", pciPathName(0, 0x65, 0, 0, true))
+	assert.Equal(t, "P1p3s0", pciPathName(1, 3, 0, 0, false))
+
+	assert.Equal(t, "", portSuffix("", 0))
+	assert.Equal(t, "d1", portSuffix("", 1))
+	assert.Equal(t, "np0", portSuffix("p0", 1))
+
+	s, err := usbPortName("1-1.2:1.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "u1u2", s)
+
+	s, err = usbPortName("2-4:2.1")
+	assert.Nil(t, err)
+	assert.Equal(t, "u4c2i1", s)
+
+	_, err = usbPortName("usb1")
+	assert.NotNil(t, err)
+
+	// dev_id is only a fallback for infiniband, and only when dev_port is 0
+	assert.Equal(t, uint64(1), devPortOrID("1", "0x0", false))
+	assert.Equal(t, uint64(0), devPortOrID("0", "0x1", false))
+	assert.Equal(t, uint64(1), devPortOrID("0", "0x1", true))
+	assert.Equal(t, uint64(2), devPortOrID("2", "0x1", true))
+	assert.Equal(t, uint64(0), devPortOrID("", "", true))
+}
+
+func TestNetIDMacName(t *testing.T) {
+	ib, _ := net.ParseMAC("80:00:02:08:fe:80:00:00:00:00:00:00:00:02:c9:03:00:0a:0b:0c")
+	for _, tc := range []struct {
+		prefix string
+		arphrd int
+		mac    net.HardwareAddr
+		want   string
+	}{
+		{"en", arphrdEther, net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}, "enx001122334455"},
+		{"wl", arphrdEther, net.HardwareAddr{0xa0, 0xb1, 0xc2, 0xd3, 0xe4, 0xf5}, "wlxa0b1c2d3e4f5"},
+		{"ib", arphrdInfiniband, ib, ""},
+		{"sl", arphrdSlip, net.HardwareAddr{}, ""},
+		{"en", arphrdEther, net.HardwareAddr{0x00, 0x11, 0x22, 0x33}, ""},
+	} {
+		assert.Equal(t, tc.want, macName(tc.prefix, tc.arphrd, tc.mac), tc.want)
+	}
+}
+
+func TestNetIDARI(t *testing.T) {
+	// 0000:3b:01.2 with ARI is function 10; net_id keeps the slot: enp59s1f10
+	_, bus, slot, fn, err := parsePCIAddress("0000:3b:01.2")
+	assert.Nil(t, err)
+	fn = ariFunction(slot, fn)
+	assert.Equal(t, "p59s1f10", pciPathName(0, bus, slot, fn, true))
+
+	_, bus, slot, fn, err = parsePCIAddress("0000:3b:00.1")
+	assert.Nil(t, err)
+	assert.Equal(t, "p59s0f1", pciPathName(0, bus, slot, ariFunction(slot, fn), false))
+}
+
+func TestBridgeSlotAllowed(t *testing.T) {
+	scheme := func(name string) *NamingScheme {
+		s, err := LookupNamingScheme(name)
+		assert.Nil(t, err)
+		return s
+	}
+
+	// the device's own slot is always used
+	assert.True(t, bridgeSlotAllowed(scheme("v255"), false, false))
+
+	assert.True(t, bridgeSlotAllowed(scheme("v245"), true, false))
+	assert.False(t, bridgeSlotAllowed(scheme("v247"), true, true))
+	assert.False(t, bridgeSlotAllowed(scheme("v251"), true, false))
+	assert.True(t, bridgeSlotAllowed(scheme("v251"), true, true))
+	assert.True(t, bridgeSlotAllowed(scheme("v254"), true, true))
+	assert.False(t, bridgeSlotAllowed(scheme("v255"), true, true))
+}
+
+func TestLookupNamingScheme(t *testing.T) {
+	s, err := LookupNamingScheme("latest")
+	assert.Nil(t, err)
+	assert.Equal(t, "v255", s.Name)
+
+	s, err = LookupNamingScheme("v238")
+	assert.Nil(t, err)
+	assert.False(t, s.has(namingSRIOVV))
+
+	s, err = LookupNamingScheme("v249")
+	assert.Nil(t, err)
+	assert.True(t, s.has(naming16BitIndex))
+	assert.False(t, s.has(namingBridgeMultifunctionSlot))
+
+	_, err = LookupNamingScheme("v1")
+	assert.NotNil(t, err)
+}
+
+func TestNetDeviceComputeNames(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	e := ctx.NewEnumerate()
+	defer e.Free()
+
+	err := e.MatchSubsystem("net")
+	assert.Nil(t, err)
+
+	ds, err := e.Devices(nil)
+	assert.Nil(t, err)
+	defer FreeDevices(ds)
+
+	for _, d := range ds {
+		n, _ := NewNetDevice(d)
+
+		// compare with what udevd computed, using the scheme it ran with
+		schemeName := d.Get("ID_NET_NAMING_SCHEME")
+		scheme, err := LookupNamingScheme(schemeName)
+		if err != nil {
+			t.Skipf("unknown naming scheme %q", schemeName)
+		}
+
+		names, err := n.ComputeNames(scheme)
+		if err == ErrNetNamingUnsupported {
+			continue
+		}
+		assert.Nil(t, err)
+		spew.Dump(n.InterfaceName(), names)
+
+		// only devices processed by udevd carry the names to compare with
+		if schemeName != "" {
+			assert.Empty(t, names.Mismatches(n.PredictableNames()))
+		}
+	}
+}
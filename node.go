@@ -0,0 +1,224 @@
+package goudev
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	nodeNameRegexp = regexp.MustCompile(`^node(\d+)$`)
+
+	ErrNotNumaNode     = errors.New("udev: device is not a numa node")
+	ErrNotHotpluggable = errors.New("udev: device can not be hotplugged")
+	ErrBadCpuListTmpl  = "udev: bad cpu list %q"
+)
+
+// ParseCpuList parses the kernel list format, e.g. 0-3,8,10-11
+func ParseCpuList(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var cs []int
+	for _, r := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(r, "-")
+		a, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf(ErrBadCpuListTmpl, s)
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil || b < a {
+				return nil, fmt.Errorf(ErrBadCpuListTmpl, s)
+			}
+		}
+		for i := a; i <= b; i++ {
+			cs = append(cs, i)
+		}
+	}
+
+	return cs, nil
+}
+
+// numbered returns N of the entries of dir named <prefix>N, ordered
+func numbered(dir string, re *regexp.Regexp) []int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var ns []int
+	for _, e := range entries {
+		if m := re.FindStringSubmatch(e.Name()); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			ns = append(ns, n)
+		}
+	}
+	sort.Ints(ns)
+
+	return ns
+}
+
+// nodeOf returns the numa node of a cpu or memory block from its nodeN link, -1 if none
+func nodeOf(d *Device) int {
+	if ns := numbered(d.SysPath(), nodeNameRegexp); len(ns) > 0 {
+		return ns[0]
+	}
+	return -1
+}
+
+// NumaNode is a typed view of a node subsystem Device, e.g. node0
+type NumaNode struct {
+	*Device
+}
+
+func NewNumaNode(d *Device) (*NumaNode, error) {
+	if d.Subsystem() != "node" || !nodeNameRegexp.MatchString(d.SysName()) {
+		return nil, ErrNotNumaNode
+	}
+
+	return &NumaNode{d}, nil
+}
+
+func (n *NumaNode) ID() int {
+	v, _ := strconv.Atoi(n.SysNumber())
+	return v
+}
+
+// Cpus returns the online cpus of the node
+func (n *NumaNode) Cpus() ([]int, error) {
+	return ParseCpuList(n.readAttribute("cpulist"))
+}
+
+// MemoryBlocks returns the indexes of the memory blocks of the node
+func (n *NumaNode) MemoryBlocks() []int {
+	return numbered(n.SysPath(), memoryNameRegexp)
+}
+
+// Distances returns the SLIT distance to every node, indexed by node id
+func (n *NumaNode) Distances() []int {
+	var ds []int
+	for _, f := range strings.Fields(n.GetAttribute("distance")) {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil
+		}
+		ds = append(ds, v)
+	}
+	return ds
+}
+
+// parseNodeMeminfo parses "Node 0 MemTotal:  5471992 kB" lines into bytes by key
+func parseNodeMeminfo(data string) map[string]uint64 {
+	m := map[string]uint64{}
+
+	s := bufio.NewScanner(strings.NewReader(data))
+	for s.Scan() {
+		fs := strings.Fields(s.Text())
+		if len(fs) < 4 || fs[0] != "Node" {
+			continue
+		}
+		v, err := strconv.ParseUint(fs[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fs) > 4 && fs[4] == "kB" {
+			v *= 1024
+		}
+		m[strings.TrimSuffix(fs[2], ":")] = v
+	}
+
+	return m
+}
+
+// Meminfo returns the node meminfo in bytes (page counts like HugePages_Total as is)
+func (n *NumaNode) Meminfo() (map[string]uint64, error) {
+	data, err := os.ReadFile(filepath.Join(n.SysPath(), "meminfo"))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNodeMeminfo(string(data)), nil
+}
+
+// NumaNodes returns the nodes ordered by id
+func (c *Context) NumaNodes() ([]*NumaNode, error) {
+	ds, err := c.subsystemDevices("node", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := make([]*NumaNode, 0, len(ds))
+	for _, d := range ds {
+		if n, err := NewNumaNode(d); err == nil {
+			ns = append(ns, n)
+			continue
+		}
+		d.Free()
+	}
+	sort.Slice(ns, func(i, j int) bool {
+		return ns[i].ID() < ns[j].ID()
+	})
+
+	return ns, nil
+}
+
+// HotplugEvent is a cpu, memory block or node uevent; cpus and memory blocks
+// report online/offline as the action
+type HotplugEvent struct {
+	Action    string
+	Subsystem string
+	SysName   string
+	Index     int
+}
+
+// HotplugEvents streams cpu, memory and node uevents until ctx is done, then closes the channel
+func (c *Context) HotplugEvents(ctx context.Context) (<-chan *HotplugEvent, error) {
+	m := c.NewMonitor()
+	for _, s := range []string{"cpu", "memory", "node"} {
+		if err := m.FilterBy(s); err != nil {
+			m.Free()
+			return nil, err
+		}
+	}
+
+	ch, err := m.DeviceChan(ctx, waitEpollTimeout)
+	if err != nil {
+		m.Free()
+		return nil, err
+	}
+
+	out := make(chan *HotplugEvent)
+	go func() {
+		defer m.Free()
+		defer close(out)
+		defer drainDeviceChan(ch)
+
+		for d := range ch {
+			ev := &HotplugEvent{
+				Action:    d.Action(),
+				Subsystem: d.Subsystem(),
+				SysName:   d.SysName(),
+			}
+			ev.Index, _ = strconv.Atoi(d.SysNumber())
+			d.Free()
+
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package goudev
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCpuList(t *testing.T) {
+	cs, err := ParseCpuList("0-3,8,10-11\n")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 8, 10, 11}, cs)
+
+	cs, err = ParseCpuList("")
+	assert.Nil(t, err)
+	assert.Empty(t, cs)
+
+	_, err = ParseCpuList("3-1")
+	assert.NotNil(t, err)
+}
+
+func TestParseNodeMeminfo(t *testing.T) {
+	m := parseNodeMeminfo("Node 0 MemTotal:        5471992 kB\nNode 0 MemFree:         3366484 kB\nNode 0 HugePages_Total:     0\n")
+	assert.Equal(t, map[string]uint64{
+		"MemTotal":        5471992 * 1024,
+		"MemFree":         3366484 * 1024,
+		"HugePages_Total": 0,
+	}, m)
+}
+
+func TestNumaNodes(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	ns, err := ctx.NumaNodes()
+	assert.Nil(t, err)
+
+	for _, n := range ns {
+		cpus, err := n.Cpus()
+		assert.Nil(t, err)
+		mi, err := n.Meminfo()
+		assert.Nil(t, err)
+		spew.Dump(n.ID(), cpus, n.Distances(), len(n.MemoryBlocks()), mi["MemTotal"])
+		n.Free()
+	}
+}
+
+func TestHotplugEvents(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	cctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	ch, err := ctx.HotplugEvents(cctx)
+	assert.Nil(t, err)
+
+	for ev := range ch {
+		spew.Dump(ev)
+	}
+}
@@ -0,0 +1,407 @@
+package goudev
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// controller transports
+const (
+	NvmeTransportPCIe = "pcie"
+	NvmeTransportTCP  = "tcp"
+	NvmeTransportRDMA = "rdma"
+	NvmeTransportFC   = "fc"
+	NvmeTransportLoop = "loop"
+)
+
+// ana_state values of a multipath path
+const (
+	AnaOptimized      = "optimized"
+	AnaNonOptimized   = "non-optimized"
+	AnaInaccessible   = "inaccessible"
+	AnaPersistentLoss = "persistent-loss"
+	AnaChange         = "change"
+)
+
+var (
+	nvmeControllerRegexp = regexp.MustCompile(`^nvme\d+$`)
+	nvmeNamespaceRegexp  = regexp.MustCompile(`^nvme\d+n\d+$`)
+	// hidden per controller path of a multipath namespace, nvme<subsys>c<ctrl>n<ns>
+	nvmePathRegexp = regexp.MustCompile(`^nvme\d+c\d+n\d+$`)
+
+	ErrNotNvmeController = errors.New("udev: device is not a nvme controller")
+	ErrNotNvmeSubsystem  = errors.New("udev: device is not a nvme subsystem")
+	ErrNotNvmeNamespace  = errors.New("udev: device is not a nvme namespace")
+)
+
+// nvmeEntries returns the resolved paths of the entries of dir matching re, ordered by name
+func nvmeEntries(dir string, re *regexp.Regexp) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ps []string
+	for _, e := range entries {
+		if !re.MatchString(e.Name()) {
+			continue
+		}
+		p, err := filepath.EvalSymlinks(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue // removed meanwhile
+		}
+		ps = append(ps, p)
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		return filepath.Base(ps[i]) < filepath.Base(ps[j])
+	})
+
+	return ps, nil
+}
+
+func devicesFromSysPaths(c *Context, ps []string) []*Device {
+	ds := make([]*Device, 0, len(ps))
+	for _, p := range ps {
+		if d, err := Devices.FromSysPath(c, p); err == nil {
+			ds = append(ds, d)
+		}
+	}
+	return ds
+}
+
+// NvmeController is a typed view of a nvme subsystem Device, e.g. nvme0
+type NvmeController struct {
+	*Device
+}
+
+func NewNvmeController(d *Device) (*NvmeController, error) {
+	if d.Subsystem() != "nvme" || !nvmeControllerRegexp.MatchString(d.SysName()) {
+		return nil, ErrNotNvmeController
+	}
+
+	return &NvmeController{d}, nil
+}
+
+func (c *NvmeController) Model() string {
+	return strings.TrimSpace(c.GetAttribute("model"))
+}
+
+func (c *NvmeController) Serial() string {
+	return strings.TrimSpace(c.GetAttribute("serial"))
+}
+
+func (c *NvmeController) FirmwareRev() string {
+	return strings.TrimSpace(c.GetAttribute("firmware_rev"))
+}
+
+// Transport returns one of the NvmeTransport* values
+func (c *NvmeController) Transport() string {
+	return c.GetAttribute("transport")
+}
+
+// Address returns the PCI address or the fabrics address, e.g. traddr=10.0.0.1,trsvcid=4420
+func (c *NvmeController) Address() string {
+	return c.GetAttribute("address")
+}
+
+// State returns the controller state (live, connecting, resetting, deleting, dead), not cached
+func (c *NvmeController) State() string {
+	return c.readAttribute("state")
+}
+
+func (c *NvmeController) SubsysNQN() string {
+	return c.GetAttribute("subsysnqn")
+}
+
+func (c *NvmeController) ControllerID() int {
+	v, _ := c.attributeInt("cntlid")
+	return v
+}
+
+// PciDevice returns the PCI function of a pcie controller, ErrNoParentDevice for fabrics
+func (c *NvmeController) PciDevice() (*PciDevice, error) {
+	p, err := c.FindParent("pci")
+	if err != nil {
+		return nil, err
+	}
+
+	return &PciDevice{p}, nil
+}
+
+// NvmeSubsystem returns the nvme-subsystem linking this controller
+func (c *NvmeController) NvmeSubsystem() (*NvmeSubsystem, error) {
+	ss, err := c.context().NvmeSubsystems()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *NvmeSubsystem
+	for _, s := range ss {
+		if _, err := os.Lstat(filepath.Join(s.SysPath(), c.SysName())); found == nil && err == nil {
+			found = s
+			continue
+		}
+		s.Free()
+	}
+	if found == nil {
+		return nil, ErrNoParentDevice
+	}
+
+	return found, nil
+}
+
+// Namespaces returns the namespaces attached to the controller. For multipath
+// namespaces these are the hidden paths, e.g. nvme0c0n1, see NvmeNamespace.Paths.
+func (c *NvmeController) Namespaces() ([]*BlockDevice, error) {
+	ds, err := c.Children(func(p *Device) FilterFn {
+		return func(td *Device) bool {
+			return td.Subsystem() == "block" && td.DeviceType() == "disk"
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bs := make([]*BlockDevice, 0, len(ds))
+	for _, d := range ds {
+		bs = append(bs, &BlockDevice{d})
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		return bs[i].SysName() < bs[j].SysName()
+	})
+
+	return bs, nil
+}
+
+// NvmeControllers returns all controllers ordered by name
+func (c *Context) NvmeControllers() ([]*NvmeController, error) {
+	ds, err := c.subsystemDevices("nvme", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]*NvmeController, 0, len(ds))
+	for _, d := range ds {
+		if ctrl, err := NewNvmeController(d); err == nil {
+			cs = append(cs, ctrl)
+			continue
+		}
+		d.Free()
+	}
+
+	return cs, nil
+}
+
+// NvmeSubsystem is a typed view of a nvme-subsystem Device, e.g. nvme-subsys0
+type NvmeSubsystem struct {
+	*Device
+}
+
+func NewNvmeSubsystem(d *Device) (*NvmeSubsystem, error) {
+	if d.Subsystem() != "nvme-subsystem" {
+		return nil, ErrNotNvmeSubsystem
+	}
+
+	return &NvmeSubsystem{d}, nil
+}
+
+func (s *NvmeSubsystem) NQN() string {
+	return s.GetAttribute("subsysnqn")
+}
+
+func (s *NvmeSubsystem) Model() string {
+	return strings.TrimSpace(s.GetAttribute("model"))
+}
+
+func (s *NvmeSubsystem) Serial() string {
+	return strings.TrimSpace(s.GetAttribute("serial"))
+}
+
+func (s *NvmeSubsystem) FirmwareRev() string {
+	return strings.TrimSpace(s.GetAttribute("firmware_rev"))
+}
+
+// IOPolicy returns the multipath policy: numa, round-robin or queue-depth
+func (s *NvmeSubsystem) IOPolicy() string {
+	return s.GetAttribute("iopolicy")
+}
+
+func (s *NvmeSubsystem) Controllers() ([]*NvmeController, error) {
+	ps, err := nvmeEntries(s.SysPath(), nvmeControllerRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := devicesFromSysPaths(s.context(), ps)
+	cs := make([]*NvmeController, 0, len(ds))
+	for _, d := range ds {
+		cs = append(cs, &NvmeController{d})
+	}
+
+	return cs, nil
+}
+
+// Namespaces returns the multipath namespaces, empty if native multipath is disabled
+func (s *NvmeSubsystem) Namespaces() ([]*NvmeNamespace, error) {
+	ps, err := nvmeEntries(s.SysPath(), nvmeNamespaceRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := devicesFromSysPaths(s.context(), ps)
+	ns := make([]*NvmeNamespace, 0, len(ds))
+	for _, d := range ds {
+		ns = append(ns, &NvmeNamespace{&BlockDevice{d}})
+	}
+
+	return ns, nil
+}
+
+// NvmeSubsystems returns all nvme subsystems ordered by name
+func (c *Context) NvmeSubsystems() ([]*NvmeSubsystem, error) {
+	ds, err := c.subsystemDevices("nvme-subsystem", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := make([]*NvmeSubsystem, 0, len(ds))
+	for _, d := range ds {
+		ss = append(ss, &NvmeSubsystem{d})
+	}
+
+	return ss, nil
+}
+
+// NvmeNamespace is a typed view of a nvme namespace block device, e.g. nvme0n1
+type NvmeNamespace struct {
+	*BlockDevice
+}
+
+func NewNvmeNamespace(d *Device) (*NvmeNamespace, error) {
+	if d.Subsystem() != "block" || !nvmeNamespaceRegexp.MatchString(d.SysName()) {
+		return nil, ErrNotNvmeNamespace
+	}
+
+	return &NvmeNamespace{&BlockDevice{d}}, nil
+}
+
+// NvmeNamespaceOf returns the namespace of a nvme disk or partition, e.g. nvme0n1p1
+func NvmeNamespaceOf(d *Device) (*NvmeNamespace, error) {
+	if d.Subsystem() == "block" && d.DeviceType() == "partition" {
+		disk, err := (&BlockDevice{d}).Disk()
+		if err != nil {
+			return nil, err
+		}
+		ns, err := NewNvmeNamespace(disk.Device)
+		if err != nil {
+			disk.Free()
+		}
+		return ns, err
+	}
+
+	p, err := Devices.FromSysPath(d.context(), d.SysPath())
+	if err != nil {
+		return nil, err
+	}
+	ns, err := NewNvmeNamespace(p)
+	if err != nil {
+		p.Free()
+	}
+	return ns, err
+}
+
+func (n *NvmeNamespace) NSID() int {
+	v, _ := n.attributeInt("nsid")
+	return v
+}
+
+func (n *NvmeNamespace) WWID() string {
+	return n.GetAttribute("wwid")
+}
+
+// IsMultipath reports whether the namespace is a native multipath head
+func (n *NvmeNamespace) IsMultipath() bool {
+	_, err := os.Stat(filepath.Join(n.SysPath(), "multipath"))
+	return err == nil
+}
+
+type NvmePath struct {
+	Name       string `json:"name"`       // e.g. nvme0c0n1, the namespace itself without multipath
+	Controller string `json:"controller"` // e.g. nvme0
+	ANAState   string `json:"ana_state,omitempty"`
+	ANAGroup   int    `json:"ana_group,omitempty"`
+}
+
+// Paths returns one path per controller the namespace is reachable through
+func (n *NvmeNamespace) Paths() ([]NvmePath, error) {
+	if !n.IsMultipath() {
+		p, err := n.FindParent("nvme")
+		if err != nil {
+			return nil, err
+		}
+		defer p.Free()
+
+		return []NvmePath{{Name: n.SysName(), Controller: p.SysName()}}, nil
+	}
+
+	ps, err := nvmeEntries(filepath.Join(n.SysPath(), "multipath"), nvmePathRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]NvmePath, 0, len(ps))
+	for _, p := range ps {
+		d, err := Devices.FromSysPath(n.context(), p)
+		if err != nil {
+			continue
+		}
+		path := NvmePath{
+			Name: d.SysName(),
+			// paths live below their controller
+			Controller: filepath.Base(filepath.Dir(p)),
+			ANAState:   d.readAttribute("ana_state"),
+		}
+		path.ANAGroup, _ = d.attributeInt("ana_grpid")
+		d.Free()
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// Controller returns the controller of the first path, the only one without multipath
+func (n *NvmeNamespace) Controller() (*NvmeController, error) {
+	paths, err := n.Paths()
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoParentDevice
+	}
+
+	d, err := Devices.FromName(n.context(), "nvme", paths[0].Controller)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NvmeController{d}, nil
+}
+
+// PciDevice returns the PCI function of the namespace's first controller
+func (n *NvmeNamespace) PciDevice() (*PciDevice, error) {
+	c, err := n.Controller()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Free()
+
+	return c.PciDevice()
+}
@@ -0,0 +1,93 @@
+package goudev
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNvmeEntries(t *testing.T) {
+	dir := t.TempDir()
+	ctrls := filepath.Join(dir, "devices")
+	subsys := filepath.Join(dir, "nvme-subsys0")
+	for _, p := range []string{
+		filepath.Join(ctrls, "nvme1", "nvme0c1n1"),
+		filepath.Join(ctrls, "nvme0", "nvme0c0n1"),
+		filepath.Join(subsys, "nvme0n1", "multipath"),
+	} {
+		assert.Nil(t, os.MkdirAll(p, 0755))
+	}
+	for _, l := range []string{"nvme0", "nvme1"} {
+		assert.Nil(t, os.Symlink(filepath.Join(ctrls, l), filepath.Join(subsys, l)))
+	}
+	mp := filepath.Join(subsys, "nvme0n1", "multipath")
+	assert.Nil(t, os.Symlink(filepath.Join(ctrls, "nvme1", "nvme0c1n1"), filepath.Join(mp, "nvme0c1n1")))
+	assert.Nil(t, os.Symlink(filepath.Join(ctrls, "nvme0", "nvme0c0n1"), filepath.Join(mp, "nvme0c0n1")))
+	assert.Nil(t, os.WriteFile(filepath.Join(subsys, "subsysnqn"), nil, 0644))
+
+	ps, err := nvmeEntries(subsys, nvmeControllerRegexp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(ctrls, "nvme0"), filepath.Join(ctrls, "nvme1")}, ps)
+
+	ps, err = nvmeEntries(subsys, nvmeNamespaceRegexp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(subsys, "nvme0n1")}, ps)
+
+	ps, err = nvmeEntries(mp, nvmePathRegexp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(ctrls, "nvme0", "nvme0c0n1"), filepath.Join(ctrls, "nvme1", "nvme0c1n1")}, ps)
+
+	ps, err = nvmeEntries(filepath.Join(dir, "missing"), nvmePathRegexp)
+	assert.Nil(t, err)
+	assert.Empty(t, ps)
+}
+
+func TestNvmeNamespace(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "block", "nvme0n1")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	ns, err := NvmeNamespaceOf(d)
+	assert.Nil(t, err)
+	defer ns.Free()
+
+	paths, err := ns.Paths()
+	assert.Nil(t, err)
+	spew.Dump(ns.NSID(), ns.WWID(), ns.IsMultipath(), paths)
+
+	c, err := ns.Controller()
+	assert.Nil(t, err)
+	defer c.Free()
+	spew.Dump(c.Model(), c.Serial(), c.FirmwareRev(), c.Transport(), c.State(), c.SubsysNQN())
+
+	if c.Transport() == NvmeTransportPCIe {
+		p, err := ns.PciDevice()
+		assert.Nil(t, err)
+		spew.Dump(p.SysName())
+		p.Free()
+	}
+}
+
+func TestNvmeSubsystems(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	ss, err := ctx.NvmeSubsystems()
+	assert.Nil(t, err)
+
+	for _, s := range ss {
+		cs, err := s.Controllers()
+		assert.Nil(t, err)
+		spew.Dump(s.NQN(), s.IOPolicy(), len(cs))
+		for _, c := range cs {
+			c.Free()
+		}
+		s.Free()
+	}
+}
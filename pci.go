@@ -0,0 +1,477 @@
+package goudev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// include/linux/ioport.h
+const (
+	PciResourceIO       = 0x00000100
+	PciResourceMem      = 0x00000200
+	PciResourcePrefetch = 0x00002000
+	PciResourceMem64    = 0x00100000
+)
+
+const (
+	pciRomResource = 6 // index of the expansion ROM in the resource attribute
+)
+
+var (
+	ErrNotPciDevice = errors.New("udev: device is not in the pci subsystem")
+	ErrNoIommuGroup = errors.New("udev: device has no iommu group")
+)
+
+// PciAddress is domain:bus:slot.function, e.g. 0000:65:00.0
+type PciAddress struct {
+	Domain   uint32
+	Bus      uint8
+	Slot     uint8
+	Function uint8
+}
+
+func ParsePciAddress(s string) (PciAddress, error) {
+	domain, bus, slot, fn, err := parsePCIAddress(s)
+	if err != nil {
+		return PciAddress{}, fmt.Errorf("udev: bad pci address %q", s)
+	}
+
+	return PciAddress{uint32(domain), uint8(bus), uint8(slot), uint8(fn)}, nil
+}
+
+func (a PciAddress) String() string {
+	return fmt.Sprintf("%04x:%02x:%02x.%d", a.Domain, a.Bus, a.Slot, a.Function)
+}
+
+// https://pci-ids.ucw.cz/read/PD/
+var pciClassNames = map[uint8]string{
+	0x00: "Unclassified device",
+	0x01: "Mass storage controller",
+	0x02: "Network controller",
+	0x03: "Display controller",
+	0x04: "Multimedia controller",
+	0x05: "Memory controller",
+	0x06: "Bridge",
+	0x07: "Communication controller",
+	0x08: "Generic system peripheral",
+	0x09: "Input device controller",
+	0x0a: "Docking station",
+	0x0b: "Processor",
+	0x0c: "Serial bus controller",
+	0x0d: "Wireless controller",
+	0x0e: "Intelligent controller",
+	0x0f: "Satellite communications controller",
+	0x10: "Encryption controller",
+	0x11: "Signal processing controller",
+	0x12: "Processing accelerators",
+	0x13: "Non-Essential Instrumentation",
+	0x40: "Coprocessor",
+	0xff: "Unassigned class",
+}
+
+var pciSubclassNames = map[uint16]string{
+	0x0000: "Non-VGA unclassified device",
+	0x0001: "VGA compatible unclassified device",
+	0x0005: "Image coprocessor",
+	0x0100: "SCSI storage controller",
+	0x0101: "IDE interface",
+	0x0102: "Floppy disk controller",
+	0x0103: "IPI bus controller",
+	0x0104: "RAID bus controller",
+	0x0105: "ATA controller",
+	0x0106: "SATA controller",
+	0x0107: "Serial Attached SCSI controller",
+	0x0108: "Non-Volatile memory controller",
+	0x0109: "Universal Flash Storage controller",
+	0x0180: "Mass storage controller",
+	0x0200: "Ethernet controller",
+	0x0201: "Token ring network controller",
+	0x0202: "FDDI network controller",
+	0x0203: "ATM network controller",
+	0x0204: "ISDN controller",
+	0x0205: "WorldFip controller",
+	0x0206: "PICMG controller",
+	0x0207: "Infiniband controller",
+	0x0208: "Fabric controller",
+	0x0280: "Network controller",
+	0x0300: "VGA compatible controller",
+	0x0301: "XGA compatible controller",
+	0x0302: "3D controller",
+	0x0380: "Display controller",
+	0x0400: "Multimedia video controller",
+	0x0401: "Multimedia audio controller",
+	0x0402: "Computer telephony device",
+	0x0403: "Audio device",
+	0x0480: "Multimedia controller",
+	0x0500: "RAM memory",
+	0x0501: "FLASH memory",
+	0x0502: "CXL",
+	0x0580: "Memory controller",
+	0x0600: "Host bridge",
+	0x0601: "ISA bridge",
+	0x0602: "EISA bridge",
+	0x0603: "MicroChannel bridge",
+	0x0604: "PCI bridge",
+	0x0605: "PCMCIA bridge",
+	0x0606: "NuBus bridge",
+	0x0607: "CardBus bridge",
+	0x0608: "RACEway bridge",
+	0x0609: "Semi-transparent PCI-to-PCI bridge",
+	0x060a: "InfiniBand to PCI host bridge",
+	0x0680: "Bridge",
+	0x0700: "Serial controller",
+	0x0701: "Parallel controller",
+	0x0702: "Multiport serial controller",
+	0x0703: "Modem",
+	0x0704: "GPIB controller",
+	0x0705: "Smard Card controller",
+	0x0780: "Communication controller",
+	0x0800: "PIC",
+	0x0801: "DMA controller",
+	0x0802: "Timer",
+	0x0803: "RTC",
+	0x0804: "PCI Hot-plug controller",
+	0x0805: "SD Host controller",
+	0x0806: "IOMMU",
+	0x0880: "System peripheral",
+	0x0899: "Timing Card",
+	0x0900: "Keyboard controller",
+	0x0901: "Digitizer Pen",
+	0x0902: "Mouse controller",
+	0x0903: "Scanner controller",
+	0x0904: "Gameport controller",
+	0x0980: "Input device controller",
+	0x0a00: "Generic Docking Station",
+	0x0a80: "Docking Station",
+	0x0c00: "FireWire (IEEE 1394)",
+	0x0c01: "ACCESS Bus",
+	0x0c02: "SSA",
+	0x0c03: "USB controller",
+	0x0c04: "Fibre Channel",
+	0x0c05: "SMBus",
+	0x0c06: "InfiniBand",
+	0x0c07: "IPMI Interface",
+	0x0c08: "SERCOS interface",
+	0x0c09: "CANBUS",
+	0x0c80: "Serial bus controller",
+	0x0d00: "IRDA controller",
+	0x0d01: "Consumer IR controller",
+	0x0d10: "RF controller",
+	0x0d11: "Bluetooth",
+	0x0d12: "Broadband",
+	0x0d20: "802.1a controller",
+	0x0d21: "802.1b controller",
+	0x0d80: "Wireless controller",
+	0x0e00: "I2O",
+	0x1000: "Network and computing encryption device",
+	0x1001: "Entertainment encryption device",
+	0x1080: "Encryption controller",
+	0x1100: "DPIO module",
+	0x1101: "Performance counters",
+	0x1110: "Communication synchronizer",
+	0x1120: "Signal processing management",
+	0x1180: "Signal processing controller",
+	0x1200: "Processing accelerators",
+	0x1201: "SDXI controller",
+}
+
+// prog-if names of the subclasses that define them, keyed by the full class code
+var pciProgIfNames = map[uint32]string{
+	0x010100: "ISA Compatibility mode-only controller",
+	0x010105: "PCI native mode-only controller",
+	0x01010a: "ISA Compatibility mode controller, supports both channels switched to PCI native mode",
+	0x01010f: "PCI native mode controller, supports both channels switched to ISA compatibility mode",
+	0x010180: "ISA Compatibility mode-only controller, supports bus mastering",
+	0x010185: "PCI native mode-only controller, supports bus mastering",
+	0x01018a: "ISA Compatibility mode controller, supports both channels switched to PCI native mode, supports bus mastering",
+	0x01018f: "PCI native mode controller, supports both channels switched to ISA compatibility mode, supports bus mastering",
+	0x010520: "ADMA single stepping",
+	0x010530: "ADMA continuous operation",
+	0x010600: "Vendor specific",
+	0x010601: "AHCI 1.0",
+	0x010602: "Serial Storage Bus",
+	0x010701: "Serial Storage Bus",
+	0x010801: "NVMHCI",
+	0x010802: "NVM Express",
+	0x010901: "UFSHCI",
+	0x030000: "VGA controller",
+	0x030001: "8514 controller",
+	0x050200: "CXL Memory Device (vendor specific)",
+	0x050210: "CXL Memory Device (CXL 2.x)",
+	0x060400: "Normal decode",
+	0x060401: "Subtractive decode",
+	0x060940: "Primary bus towards host CPU",
+	0x060980: "Secondary bus towards host CPU",
+	0x070000: "8250",
+	0x070001: "16450",
+	0x070002: "16550",
+	0x070003: "16650",
+	0x070004: "16750",
+	0x070005: "16850",
+	0x070006: "16950",
+	0x070100: "SPP",
+	0x070101: "BiDir",
+	0x070102: "ECP",
+	0x070103: "IEEE1284",
+	0x0701fe: "IEEE1284 Target",
+	0x070300: "Generic",
+	0x070301: "Hayes/16450",
+	0x070302: "Hayes/16550",
+	0x070303: "Hayes/16650",
+	0x070304: "Hayes/16750",
+	0x080000: "8259",
+	0x080001: "ISA PIC",
+	0x080002: "EISA PIC",
+	0x080010: "IO-APIC",
+	0x080020: "IO(X)-APIC",
+	0x080100: "8237",
+	0x080101: "ISA DMA",
+	0x080102: "EISA DMA",
+	0x080200: "8254",
+	0x080201: "ISA Timer",
+	0x080202: "EISA Timers",
+	0x080203: "HPET",
+	0x080300: "Generic",
+	0x080301: "ISA RTC",
+	0x080500: "Standard",
+	0x080501: "Compatible",
+	0x090400: "Generic",
+	0x090410: "Extended",
+	0x0c0000: "Generic",
+	0x0c0010: "OHCI",
+	0x0c0300: "UHCI",
+	0x0c0310: "OHCI",
+	0x0c0320: "EHCI",
+	0x0c0330: "XHCI",
+	0x0c0340: "USB4 Host Interface",
+	0x0c0380: "Unspecified",
+	0x0c03fe: "USB Device",
+	0x0c0700: "SMIC",
+	0x0c0701: "KCS",
+	0x0c0702: "BT (Block Transfer)",
+}
+
+// PciClassName returns the subclass name, or the class name for unknown subclasses
+func PciClassName(class uint32) string {
+	if s, ok := pciSubclassNames[uint16(class>>8)]; ok {
+		return s
+	}
+	if s, ok := pciClassNames[uint8(class>>16)]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Class %04x", class>>8)
+}
+
+// PciProgIfName returns the prog-if name, or "" when the subclass doesn't name it
+func PciProgIfName(class uint32) string {
+	return pciProgIfNames[class&0xffffff]
+}
+
+// PciResource is one line of the resource attribute; Index 0-5 are BARs, 6 the ROM
+// and higher indexes bridge windows
+type PciResource struct {
+	Index int
+	Start uint64
+	End   uint64
+	Flags uint64
+}
+
+func (r PciResource) Size() uint64 {
+	return r.End - r.Start + 1
+}
+
+func (r PciResource) IsIO() bool {
+	return r.Flags&PciResourceIO != 0
+}
+
+func (r PciResource) IsPrefetchable() bool {
+	return r.Flags&PciResourcePrefetch != 0
+}
+
+func (r PciResource) Is64Bit() bool {
+	return r.Flags&PciResourceMem64 != 0
+}
+
+func (r PciResource) IsROM() bool {
+	return r.Index == pciRomResource
+}
+
+// ParsePciResources parses the resource attribute, unused entries are skipped
+func ParsePciResources(data string) ([]PciResource, error) {
+	var rs []PciResource
+	for i, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("udev: bad pci resource line %q", line)
+		}
+
+		var vs [3]uint64
+		for j, f := range fields {
+			v, err := parseUint64(f)
+			if err != nil {
+				return nil, err
+			}
+			vs[j] = v
+		}
+		if vs[0] == 0 && vs[1] == 0 {
+			continue
+		}
+
+		rs = append(rs, PciResource{Index: i, Start: vs[0], End: vs[1], Flags: vs[2]})
+	}
+
+	return rs, nil
+}
+
+// PciDevice is a typed view of a pci subsystem Device
+type PciDevice struct {
+	*Device
+}
+
+func NewPciDevice(d *Device) (*PciDevice, error) {
+	if d.Subsystem() != "pci" {
+		return nil, ErrNotPciDevice
+	}
+
+	return &PciDevice{d}, nil
+}
+
+func (p *PciDevice) Address() (PciAddress, error) {
+	return ParsePciAddress(p.SysName())
+}
+
+func (p *PciDevice) attributeID(attribute string) uint16 {
+	v, _ := p.attributeUint64(attribute)
+	return uint16(v)
+}
+
+func (p *PciDevice) VendorID() uint16 {
+	return p.attributeID("vendor")
+}
+
+func (p *PciDevice) DeviceID() uint16 {
+	return p.attributeID("device")
+}
+
+func (p *PciDevice) SubsystemVendorID() uint16 {
+	return p.attributeID("subsystem_vendor")
+}
+
+func (p *PciDevice) SubsystemDeviceID() uint16 {
+	return p.attributeID("subsystem_device")
+}
+
+func (p *PciDevice) Revision() uint8 {
+	v, _ := p.attributeUint64("revision")
+	return uint8(v)
+}
+
+// Class is the 24 bit class code: class, subclass, prog-if
+func (p *PciDevice) Class() uint32 {
+	return uint32(p.pciClass())
+}
+
+func (p *PciDevice) BaseClass() uint8 {
+	return uint8(p.Class() >> 16)
+}
+
+func (p *PciDevice) SubClass() uint8 {
+	return uint8(p.Class() >> 8)
+}
+
+func (p *PciDevice) ProgIf() uint8 {
+	return uint8(p.Class())
+}
+
+func (p *PciDevice) ClassName() string {
+	return PciClassName(p.Class())
+}
+
+// ProgIfName like "NVM Express" or "XHCI"
+func (p *PciDevice) ProgIfName() string {
+	return PciProgIfName(p.Class())
+}
+
+func (p *PciDevice) IsBridge() bool {
+	return isPCIBridge(p.Device)
+}
+
+// NumaNode is -1 without NUMA affinity
+func (p *PciDevice) NumaNode() int {
+	n, err := p.attributeInt("numa_node")
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+func (p *PciDevice) IommuGroup() (int, error) {
+	l, err := os.Readlink(filepath.Join(p.SysPath(), "iommu_group"))
+	if err != nil {
+		return 0, ErrNoIommuGroup
+	}
+
+	return strconv.Atoi(filepath.Base(l))
+}
+
+// CurrentLinkSpeed like "8.0 GT/s PCIe", empty for conventional PCI
+func (p *PciDevice) CurrentLinkSpeed() string {
+	return strings.TrimSpace(p.GetAttribute("current_link_speed"))
+}
+
+func (p *PciDevice) MaxLinkSpeed() string {
+	return strings.TrimSpace(p.GetAttribute("max_link_speed"))
+}
+
+func (p *PciDevice) CurrentLinkWidth() int {
+	n, _ := p.attributeInt("current_link_width")
+	return n
+}
+
+func (p *PciDevice) MaxLinkWidth() int {
+	n, _ := p.attributeInt("max_link_width")
+	return n
+}
+
+// Enabled reports a non-zero enable count; drivers and userspace each hold one
+func (p *PciDevice) Enabled() bool {
+	n, err := p.attributeInt("enable")
+	return err == nil && n > 0
+}
+
+func (p *PciDevice) Resources() ([]PciResource, error) {
+	data, err := os.ReadFile(filepath.Join(p.SysPath(), "resource"))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePciResources(string(data))
+}
+
+// BridgeChain returns the bridges above the device, nearest first, ending with the root port
+func (p *PciDevice) BridgeChain() ([]*PciDevice, error) {
+	var chain []*PciDevice
+
+	cur := p.Device
+	for {
+		parent, err := cur.FindParent("pci")
+		if err == ErrNoParentDevice {
+			return chain, nil
+		}
+		if err != nil {
+			for _, b := range chain {
+				b.Free()
+			}
+			return nil, err
+		}
+
+		chain = append(chain, &PciDevice{parent})
+		cur = parent
+	}
+}
@@ -0,0 +1,86 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePciAddress(t *testing.T) {
+	a, err := ParsePciAddress("0000:65:00.0")
+	assert.Nil(t, err)
+	assert.Equal(t, PciAddress{0, 0x65, 0, 0}, a)
+	assert.Equal(t, "0000:65:00.0", a.String())
+
+	_, err = ParsePciAddress("pci0000:00")
+	assert.NotNil(t, err)
+}
+
+func TestParsePciResources(t *testing.T) {
+	rs, err := ParsePciResources(`0x00000000fb000000 0x00000000fbffffff 0x0000000000040200
+0x0000006000000000 0x00000060ffffffff 0x000000000014220c
+0x0000000000000000 0x0000000000000000 0x0000000000000000
+0x000000000000e000 0x000000000000e07f 0x0000000000040101
+0x0000000000000000 0x0000000000000000 0x0000000000000000
+0x0000000000000000 0x0000000000000000 0x0000000000000000
+0x00000000fc000000 0x00000000fc07ffff 0x0000000000046200
+`)
+	assert.Nil(t, err)
+	assert.Len(t, rs, 4)
+
+	assert.Equal(t, 0, rs[0].Index)
+	assert.Equal(t, uint64(16<<20), rs[0].Size())
+	assert.False(t, rs[0].IsPrefetchable())
+
+	assert.Equal(t, 1, rs[1].Index)
+	assert.True(t, rs[1].IsPrefetchable())
+	assert.True(t, rs[1].Is64Bit())
+
+	assert.Equal(t, 3, rs[2].Index)
+	assert.True(t, rs[2].IsIO())
+
+	assert.True(t, rs[3].IsROM())
+}
+
+func TestPciClassName(t *testing.T) {
+	assert.Equal(t, "Ethernet controller", PciClassName(0x020000))
+	assert.Equal(t, "Non-Volatile memory controller", PciClassName(0x010802))
+	assert.Equal(t, "Multimedia controller", PciClassName(0x04ee00))
+	assert.Equal(t, "Universal Flash Storage controller", PciClassName(0x010901))
+	assert.Equal(t, "Wireless controller", PciClassName(0x0d8000))
+}
+
+func TestPciProgIfName(t *testing.T) {
+	assert.Equal(t, "NVM Express", PciProgIfName(0x010802))
+	assert.Equal(t, "XHCI", PciProgIfName(0x0c0330))
+	assert.Equal(t, "AHCI 1.0", PciProgIfName(0x010601))
+	assert.Equal(t, "", PciProgIfName(0x020000))
+}
+
+func TestPciDevice(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "pci", "0000:65:00.0")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	p, err := NewPciDevice(d)
+	assert.Nil(t, err)
+	assert.NotZero(t, p.VendorID())
+
+	spew.Dump(p.VendorID(), p.DeviceID(), p.SubsystemVendorID(), p.SubsystemDeviceID(), p.Revision())
+	spew.Dump(p.ClassName(), p.NumaNode(), p.CurrentLinkSpeed(), p.CurrentLinkWidth(), p.MaxLinkSpeed(), p.MaxLinkWidth())
+
+	rs, err := p.Resources()
+	assert.Nil(t, err)
+	spew.Dump(rs)
+
+	chain, err := p.BridgeChain()
+	assert.Nil(t, err)
+	for _, b := range chain {
+		assert.True(t, b.IsBridge())
+		b.Free()
+	}
+}
@@ -0,0 +1,215 @@
+package goudev
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// power/control values
+const (
+	PowerControlAuto = "auto"
+	PowerControlOn   = "on"
+)
+
+// power/runtime_status values
+const (
+	RuntimeActive      = "active"
+	RuntimeSuspended   = "suspended"
+	RuntimeSuspending  = "suspending"
+	RuntimeResuming    = "resuming"
+	RuntimeError       = "error"
+	RuntimeUnsupported = "unsupported"
+)
+
+var (
+	ErrNoRuntimePM         = errors.New("udev: device does not support runtime pm")
+	ErrNoAutosuspend       = errors.New("udev: driver does not use autosuspend")
+	ErrNoWakeup            = errors.New("udev: device is not wakeup capable")
+	ErrInvalidPowerControl = errors.New("udev: power/control must be auto or on")
+)
+
+// PowerError records the power/ attribute and the device a read or write failed on
+type PowerError struct {
+	SysPath   string
+	Attribute string
+	Value     string // the value written, "" for reads
+	Err       error
+}
+
+func (e *PowerError) Error() string {
+	if e.Value != "" {
+		return fmt.Sprintf("udev: set %s=%s on %s: %v", e.Attribute, e.Value, e.SysPath, e.Err)
+	}
+	return fmt.Sprintf("udev: read %s on %s: %v", e.Attribute, e.SysPath, e.Err)
+}
+
+func (e *PowerError) Unwrap() error {
+	return e.Err
+}
+
+// powerAttribute reads sysfs on every call, runtime pm state changes under a live Device
+func (d *Device) powerAttribute(attribute string, missing error) (string, error) {
+	v := d.readAttribute("power/" + attribute)
+	if v == "" {
+		return "", &PowerError{SysPath: d.SysPath(), Attribute: "power/" + attribute, Err: missing}
+	}
+
+	return v, nil
+}
+
+func (d *Device) setPowerAttribute(attribute, value string) error {
+	if err := d.SetAttribute("power/"+attribute, value); err != nil {
+		return &PowerError{SysPath: d.SysPath(), Attribute: "power/" + attribute, Value: value, Err: err}
+	}
+
+	return nil
+}
+
+// PowerControl returns auto if the device may runtime suspend, on if userspace forbids it
+func (d *Device) PowerControl() (string, error) {
+	return d.powerAttribute("control", ErrNoRuntimePM)
+}
+
+func (d *Device) SetPowerControl(control string) error {
+	if control != PowerControlAuto && control != PowerControlOn {
+		return &PowerError{SysPath: d.SysPath(), Attribute: "power/control", Value: control, Err: ErrInvalidPowerControl}
+	}
+	if _, err := d.PowerControl(); err != nil {
+		return err
+	}
+
+	return d.setPowerAttribute("control", control)
+}
+
+// RuntimeStatus returns one of the Runtime* values, unsupported if the attribute is missing
+func (d *Device) RuntimeStatus() string {
+	v, err := d.powerAttribute("runtime_status", ErrNoRuntimePM)
+	if err != nil {
+		return RuntimeUnsupported
+	}
+	return v
+}
+
+// AutosuspendDelay is the idle time before an autosuspend, negative disables autosuspend
+func (d *Device) AutosuspendDelay() (time.Duration, error) {
+	v, err := d.powerAttribute("autosuspend_delay_ms", ErrNoAutosuspend)
+	if err != nil {
+		return 0, err
+	}
+
+	ms, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &PowerError{SysPath: d.SysPath(), Attribute: "power/autosuspend_delay_ms", Err: err}
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func (d *Device) SetAutosuspendDelay(delay time.Duration) error {
+	return d.setPowerAttribute("autosuspend_delay_ms", strconv.FormatInt(delay.Milliseconds(), 10))
+}
+
+func (d *Device) WakeupEnabled() (bool, error) {
+	v, err := d.powerAttribute("wakeup", ErrNoWakeup)
+	if err != nil {
+		return false, err
+	}
+
+	return v == "enabled", nil
+}
+
+func (d *Device) SetWakeup(enabled bool) error {
+	if _, err := d.WakeupEnabled(); err != nil {
+		return err
+	}
+
+	v := "disabled"
+	if enabled {
+		v = "enabled"
+	}
+	return d.setPowerAttribute("wakeup", v)
+}
+
+func (d *Device) runtimeTime(attribute string) (time.Duration, error) {
+	v, err := d.powerAttribute(attribute, ErrNoRuntimePM)
+	if err != nil {
+		return 0, err
+	}
+
+	ms, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, &PowerError{SysPath: d.SysPath(), Attribute: "power/" + attribute, Err: err}
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// RuntimeActiveTime is the accumulated time spent active
+func (d *Device) RuntimeActiveTime() (time.Duration, error) {
+	return d.runtimeTime("runtime_active_time")
+}
+
+func (d *Device) RuntimeSuspendedTime() (time.Duration, error) {
+	return d.runtimeTime("runtime_suspended_time")
+}
+
+// RuntimePMBlocker is a device that keeps itself, and its parents, from runtime suspending
+type RuntimePMBlocker struct {
+	SysPath   string `json:"syspath"`
+	Subsystem string `json:"subsystem"`
+	Driver    string `json:"driver,omitempty"`
+	Control   string `json:"control"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+}
+
+// runtimePMBlocker returns why a device blocks runtime suspend, "" if it does not
+func runtimePMBlocker(control, status string, usage int) string {
+	switch {
+	case status == RuntimeError:
+		return "runtime pm error"
+	case control == PowerControlOn:
+		return "power/control is on"
+	case status == RuntimeActive && usage > 0:
+		// runtime_usage is only present with CONFIG_PM_ADVANCED_DEBUG
+		return fmt.Sprintf("in use (usage count %d)", usage)
+	}
+	return ""
+}
+
+// RuntimePMBlockers returns the devices with runtime pm that are kept active
+func (c *Context) RuntimePMBlockers() ([]*RuntimePMBlocker, error) {
+	e := c.NewEnumerate()
+	defer e.Free()
+
+	ds, err := e.Devices(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer FreeDevices(ds)
+
+	var bs []*RuntimePMBlocker
+	for _, d := range ds {
+		control, err := d.PowerControl()
+		if err != nil {
+			continue
+		}
+		status := d.RuntimeStatus()
+		usage, _ := d.attributeInt("power/runtime_usage")
+
+		if reason := runtimePMBlocker(control, status, usage); reason != "" {
+			bs = append(bs, &RuntimePMBlocker{
+				SysPath:   d.SysPath(),
+				Subsystem: d.Subsystem(),
+				Driver:    d.Driver(),
+				Control:   control,
+				Status:    status,
+				Reason:    reason,
+			})
+		}
+	}
+
+	return bs, nil
+}
@@ -0,0 +1,199 @@
+package goudev
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// POWER_SUPPLY_TYPE values
+const (
+	PowerSupplyBattery = "Battery"
+	PowerSupplyMains   = "Mains"
+	PowerSupplyUSB     = "USB"
+	PowerSupplyUPS     = "UPS"
+)
+
+// POWER_SUPPLY_STATUS values
+const (
+	PowerSupplyCharging    = "Charging"
+	PowerSupplyDischarging = "Discharging"
+	PowerSupplyNotCharging = "Not charging"
+	PowerSupplyFull        = "Full"
+	PowerSupplyUnknown     = "Unknown"
+)
+
+var (
+	ErrNotPowerSupply = errors.New("udev: device is not in the power_supply subsystem")
+)
+
+// PowerSupplyState is a snapshot of a power_supply device. Values keep the sysfs
+// units: µWh, µAh, µV, µA and µW; -1 means the supply does not report it.
+type PowerSupplyState struct {
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	Online           bool   `json:"online"`
+	Present          bool   `json:"present"`
+	Status           string `json:"status,omitempty"`
+	Capacity         int    `json:"capacity"` // percent
+	CapacityLevel    string `json:"capacity_level,omitempty"`
+	EnergyNow        int64  `json:"energy_now"`
+	EnergyFull       int64  `json:"energy_full"`
+	EnergyFullDesign int64  `json:"energy_full_design"`
+	ChargeNow        int64  `json:"charge_now"`
+	ChargeFull       int64  `json:"charge_full"`
+	ChargeFullDesign int64  `json:"charge_full_design"`
+	VoltageNow       int64  `json:"voltage_now"`
+	VoltageMinDesign int64  `json:"voltage_min_design"`
+	CurrentNow       int64  `json:"current_now"`
+	PowerNow         int64  `json:"power_now"`
+	CycleCount       int    `json:"cycle_count"`
+	Technology       string `json:"technology,omitempty"`
+	Manufacturer     string `json:"manufacturer,omitempty"`
+	ModelName        string `json:"model_name,omitempty"`
+	SerialNumber     string `json:"serial_number,omitempty"`
+}
+
+// Health returns full/design capacity in percent, -1 if unknown
+func (s *PowerSupplyState) Health() float64 {
+	switch {
+	case s.EnergyFull > 0 && s.EnergyFullDesign > 0:
+		return float64(s.EnergyFull) * 100 / float64(s.EnergyFullDesign)
+	case s.ChargeFull > 0 && s.ChargeFullDesign > 0:
+		return float64(s.ChargeFull) * 100 / float64(s.ChargeFullDesign)
+	}
+	return -1
+}
+
+// PowerSupply is a typed view of a power_supply subsystem Device
+type PowerSupply struct {
+	*Device
+}
+
+func NewPowerSupply(d *Device) (*PowerSupply, error) {
+	if d.Subsystem() != "power_supply" {
+		return nil, ErrNotPowerSupply
+	}
+
+	return &PowerSupply{d}, nil
+}
+
+// value reads sysfs fresh for enumerated devices. Monitor events carry the values
+// as uevent properties; those stay valid after a remove, when sysfs is gone.
+func (p *PowerSupply) value(name string) string {
+	if p.Action() != "" {
+		return p.Get("POWER_SUPPLY_" + strings.ToUpper(name))
+	}
+	return p.readAttribute(name)
+}
+
+func (p *PowerSupply) int64Value(name string) int64 {
+	v, err := strconv.ParseInt(p.value(name), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+func (p *PowerSupply) Type() string {
+	return p.value("type")
+}
+
+func (p *PowerSupply) IsBattery() bool {
+	return p.Type() == PowerSupplyBattery
+}
+
+func (p *PowerSupply) State() *PowerSupplyState {
+	s := &PowerSupplyState{
+		Name:             p.SysName(),
+		Type:             p.Type(),
+		Online:           p.value("online") == "1",
+		Present:          p.value("present") == "1",
+		Status:           p.value("status"),
+		Capacity:         int(p.int64Value("capacity")),
+		CapacityLevel:    p.value("capacity_level"),
+		EnergyNow:        p.int64Value("energy_now"),
+		EnergyFull:       p.int64Value("energy_full"),
+		EnergyFullDesign: p.int64Value("energy_full_design"),
+		ChargeNow:        p.int64Value("charge_now"),
+		ChargeFull:       p.int64Value("charge_full"),
+		ChargeFullDesign: p.int64Value("charge_full_design"),
+		VoltageNow:       p.int64Value("voltage_now"),
+		VoltageMinDesign: p.int64Value("voltage_min_design"),
+		CurrentNow:       p.int64Value("current_now"),
+		PowerNow:         p.int64Value("power_now"),
+		CycleCount:       int(p.int64Value("cycle_count")),
+		Technology:       p.value("technology"),
+		Manufacturer:     p.value("manufacturer"),
+		ModelName:        p.value("model_name"),
+		SerialNumber:     p.value("serial_number"),
+	}
+
+	// mains/usb supplies have no present attribute
+	if s.Type != PowerSupplyBattery && p.value("present") == "" {
+		s.Present = true
+	}
+
+	return s
+}
+
+// PowerSupplies returns all power supplies ordered by name
+func (c *Context) PowerSupplies() ([]*PowerSupply, error) {
+	ds, err := c.subsystemDevices("power_supply", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := make([]*PowerSupply, 0, len(ds))
+	for _, d := range ds {
+		ps = append(ps, &PowerSupply{d})
+	}
+
+	return ps, nil
+}
+
+type PowerSupplyEvent struct {
+	Action string
+	State  *PowerSupplyState
+}
+
+// PowerSupplyEvents streams parsed power_supply uevents until ctx is done, then
+// closes the channel. Batteries usually emit a change event on status changes and
+// every few percent of capacity.
+func (c *Context) PowerSupplyEvents(ctx context.Context) (<-chan *PowerSupplyEvent, error) {
+	m := c.NewMonitor()
+	if err := m.FilterBy("power_supply"); err != nil {
+		m.Free()
+		return nil, err
+	}
+
+	ch, err := m.DeviceChan(ctx, waitEpollTimeout)
+	if err != nil {
+		m.Free()
+		return nil, err
+	}
+
+	out := make(chan *PowerSupplyEvent)
+	go func() {
+		defer m.Free()
+		defer close(out)
+		defer drainDeviceChan(ch)
+
+		for d := range ch {
+			ev := &PowerSupplyEvent{
+				Action: d.Action(),
+				State:  (&PowerSupply{d}).State(),
+			}
+			d.Free()
+
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
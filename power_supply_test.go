@@ -0,0 +1,49 @@
+package goudev
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPowerSupplyHealth(t *testing.T) {
+	s := &PowerSupplyState{EnergyFull: 45000000, EnergyFullDesign: 50000000, ChargeFull: -1, ChargeFullDesign: -1}
+	assert.Equal(t, 90.0, s.Health())
+
+	s = &PowerSupplyState{EnergyFull: -1, EnergyFullDesign: -1, ChargeFull: 3000000, ChargeFullDesign: 4000000}
+	assert.Equal(t, 75.0, s.Health())
+
+	s = &PowerSupplyState{EnergyFull: -1, EnergyFullDesign: -1, ChargeFull: -1, ChargeFullDesign: -1}
+	assert.Equal(t, -1.0, s.Health())
+}
+
+func TestPowerSupplies(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	ps, err := ctx.PowerSupplies()
+	assert.Nil(t, err)
+
+	for _, p := range ps {
+		spew.Dump(p.State())
+		p.Free()
+	}
+}
+
+func TestPowerSupplyEvents(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	cctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	ch, err := ctx.PowerSupplyEvents(cctx)
+	assert.Nil(t, err)
+
+	for ev := range ch {
+		spew.Dump(ev)
+	}
+}
@@ -0,0 +1,78 @@
+package goudev
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimePMBlocker(t *testing.T) {
+	assert.Equal(t, "", runtimePMBlocker(PowerControlAuto, RuntimeSuspended, 0))
+	assert.Equal(t, "", runtimePMBlocker(PowerControlAuto, RuntimeActive, 0))
+	assert.Equal(t, "power/control is on", runtimePMBlocker(PowerControlOn, RuntimeActive, 0))
+	assert.Equal(t, "runtime pm error", runtimePMBlocker(PowerControlAuto, RuntimeError, 0))
+	assert.Equal(t, "in use (usage count 2)", runtimePMBlocker(PowerControlAuto, RuntimeActive, 2))
+}
+
+func TestDevicePowerControl(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "pci", "0000:65:00.0")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	control, err := d.PowerControl()
+	assert.Nil(t, err)
+	spew.Dump(control, d.RuntimeStatus())
+	spew.Dump(d.RuntimeActiveTime())
+	spew.Dump(d.AutosuspendDelay())
+
+	err = d.SetPowerControl("off")
+	assert.True(t, errors.Is(err, ErrInvalidPowerControl))
+
+	var perr *PowerError
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, "power/control", perr.Attribute)
+}
+
+func TestRuntimeActiveTimeResample(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	ds, err := ctx.subsystemDevices("pci", nil)
+	assert.Nil(t, err)
+	defer FreeDevices(ds)
+
+	for _, d := range ds {
+		// power/control on keeps it from suspending between the samples
+		if control, _ := d.PowerControl(); control != PowerControlOn || d.RuntimeStatus() != RuntimeActive {
+			continue
+		}
+		before, err := d.RuntimeActiveTime()
+		if err != nil {
+			continue
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		after, err := d.RuntimeActiveTime()
+		assert.Nil(t, err)
+		assert.Equal(t, RuntimeActive, d.RuntimeStatus())
+		assert.Greater(t, after, before)
+		return
+	}
+	t.Skip("no runtime active pci device")
+}
+
+func TestRuntimePMBlockers(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	bs, err := ctx.RuntimePMBlockers()
+	assert.Nil(t, err)
+	spew.Dump(bs)
+}
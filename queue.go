@@ -0,0 +1,243 @@
+package goudev
+
+// #include <libudev.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	settlePollInterval = 50 * time.Millisecond
+	settleQuietPeriod  = time.Second
+	kernelSeqnumPath   = "/sys/kernel/uevent_seqnum"
+
+	udevCtrlMagic       = 0xdead1dea
+	udevCtrlMessageSize = 16 + 4 + 4 + 256 // version, magic, type, value
+	udevCtrlEndMessages = 0
+	udevCtrlPing        = 7
+)
+
+// variables so tests can run Settle against a fake udevd
+var (
+	udevQueuePath   = "/run/udev/queue"
+	udevControlPath = "/run/udev/control"
+)
+
+var (
+	ErrSettleTimeout = errors.New("udev: settle timed out")
+)
+
+// Queue wraps udev_queue, which reflects the state of /run/udev/queue
+type Queue struct {
+	udevQueue *C.struct_udev_queue
+}
+
+func (q *Queue) Free() {
+	if q.udevQueue != nil {
+		C.udev_queue_unref(q.udevQueue)
+	}
+}
+
+// IsActive reports whether udevd is running
+func (q *Queue) IsActive() bool {
+	return C.udev_queue_get_udev_is_active(q.udevQueue) != 0
+}
+
+// IsEmpty reports whether udevd has no queued or running events
+func (q *Queue) IsEmpty() bool {
+	return C.udev_queue_get_queue_is_empty(q.udevQueue) != 0
+}
+
+func (c *Context) IsQueueEmpty() bool {
+	q := c.NewQueue()
+	defer q.Free()
+
+	return q.IsEmpty()
+}
+
+// KernelSeqnum returns the seqnum of the last uevent sent by the kernel
+func KernelSeqnum() (uint64, error) {
+	data, err := os.ReadFile(kernelSeqnumPath)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// the ping returns once udevd has read every uevent the kernel sent before it,
+// see udev_ctrl_send_ping() in systemd src/udev/udev-ctrl.c
+func pingUdevd(ctx context.Context, path string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unixpacket", path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err = conn.Write(udevCtrlMessage(udevCtrlPing)); err != nil {
+		return err
+	}
+	// udevd older than v246 closes the connection after each message
+	conn.Write(udevCtrlMessage(udevCtrlEndMessages))
+
+	// udevd closes the connection when the messages are handled
+	buf := make([]byte, udevCtrlMessageSize)
+	for {
+		if _, err = conn.Read(buf); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// struct udev_ctrl_msg_wire
+func udevCtrlMessage(typ uint32) []byte {
+	b := make([]byte, udevCtrlMessageSize)
+	copy(b, "udev-goudev")
+	binary.NativeEndian.PutUint32(b[16:], udevCtrlMagic)
+	binary.NativeEndian.PutUint32(b[20:], typ)
+	return b
+}
+
+func queueIsEmpty(path string) bool {
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// settleState decides when the target uevent has been handled by udevd. An empty
+// queue only counts once udevd is known to have received the target event: after
+// a ping, or after a processed event with a seqnum >= target was seen.
+type settleState struct {
+	queuePath string
+	target    uint64
+	// SettleSeqnum: seeing the target event itself is enough
+	exact      bool
+	pinged     bool
+	seen       uint64 // highest seqnum processed by udevd since listening
+	processed  bool
+	emptySince time.Time
+}
+
+func (s *settleState) onDevice(d *Device) {
+	n := d.SequenceNumber()
+	if n > s.seen {
+		s.seen = n
+	}
+	if n == s.target {
+		s.processed = true
+	}
+}
+
+func (s *settleState) done(now time.Time) bool {
+	if s.exact && s.processed {
+		return true
+	}
+	if !queueIsEmpty(s.queuePath) {
+		s.emptySince = time.Time{}
+		return false
+	}
+	if s.pinged || s.seen >= s.target {
+		return true
+	}
+
+	// without the ping (the control socket needs root) the target may have been
+	// processed before the monitor started, trust an idle queue after a quiet period
+	if s.emptySince.IsZero() {
+		s.emptySince = now
+	}
+	return now.Sub(s.emptySince) >= settleQuietPeriod
+}
+
+// Settle waits until udevd has processed all events the kernel sent so far, like
+// `udevadm settle`. timeout <= 0 means only ctx bounds the wait.
+func (c *Context) Settle(ctx context.Context, timeout time.Duration) error {
+	return c.settle(ctx, timeout, 0)
+}
+
+// SettleSeqnum waits until the uevent with seqnum (e.g. Device.SequenceNumber())
+// has been processed, ignoring events queued after it.
+func (c *Context) SettleSeqnum(ctx context.Context, timeout time.Duration, seqnum uint64) error {
+	return c.settle(ctx, timeout, seqnum)
+}
+
+func (c *Context) settle(ctx context.Context, timeout time.Duration, seqnum uint64) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// udevd is not running, nothing will be processed
+	if _, err := os.Stat(udevControlPath); err != nil {
+		return nil
+	}
+
+	// listen before looking at the queue, so the event can not slip through
+	mctx, cancel := context.WithCancel(ctx)
+	m := c.NewMonitor()
+	defer m.Free()
+	ch, err := m.DeviceChan(mctx, waitEpollTimeout)
+	if err != nil {
+		cancel()
+		return err
+	}
+	defer drainDeviceChan(ch)
+	defer cancel()
+
+	s := &settleState{queuePath: udevQueuePath, target: seqnum, exact: seqnum != 0}
+	if !s.exact {
+		if s.target, err = KernelSeqnum(); err != nil {
+			return err
+		}
+	}
+
+	if err = pingUdevd(ctx, udevControlPath); err == nil {
+		s.pinged = true
+	} else if ctx.Err() != nil {
+		return settleErr(ctx)
+	}
+
+	ticker := time.NewTicker(settlePollInterval)
+	defer ticker.Stop()
+
+	for !s.done(time.Now()) {
+		select {
+		case <-ctx.Done():
+			return settleErr(ctx)
+		case <-ticker.C:
+		case d, ok := <-ch:
+			if !ok {
+				ch = nil
+				continue
+			}
+			s.onDevice(d)
+			d.Free()
+		}
+	}
+
+	return nil
+}
+
+func settleErr(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrSettleTimeout
+	}
+	return ctx.Err()
+}
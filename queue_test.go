@@ -0,0 +1,162 @@
+package goudev
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettle(t *testing.T) {
+	c := NewContext()
+	defer c.Free()
+
+	spew.Dump(c.IsQueueEmpty())
+
+	err := c.Settle(context.Background(), 5*time.Second)
+	assert.Nil(t, err)
+}
+
+func TestSettleSeqnum(t *testing.T) {
+	c := NewContext()
+	defer c.Free()
+
+	seqnum, err := KernelSeqnum()
+	assert.Nil(t, err)
+	spew.Dump(seqnum)
+
+	err = c.SettleSeqnum(context.Background(), 5*time.Second, seqnum)
+	assert.Nil(t, err)
+}
+
+func TestSettleState(t *testing.T) {
+	dir := t.TempDir()
+	queue := filepath.Join(dir, "queue")
+	now := time.Now()
+
+	// empty queue, but udevd has not been seen receiving the event yet
+	s := &settleState{queuePath: queue, target: 100}
+	assert.False(t, s.done(now))
+
+	s.seen = 99
+	assert.False(t, s.done(now))
+
+	s.seen = 100
+	assert.True(t, s.done(now))
+
+	assert.Nil(t, os.WriteFile(queue, nil, 0644))
+	assert.False(t, s.done(now))
+	assert.Nil(t, os.Remove(queue))
+
+	s = &settleState{queuePath: queue, target: 100, pinged: true}
+	assert.True(t, s.done(now))
+
+	// SettleSeqnum returns on the event itself, even with later events queued
+	assert.Nil(t, os.WriteFile(queue, nil, 0644))
+	s = &settleState{queuePath: queue, target: 100, exact: true, processed: true}
+	assert.True(t, s.done(now))
+	assert.Nil(t, os.Remove(queue))
+
+	// no ping possible: an idle queue is trusted after the quiet period
+	s = &settleState{queuePath: queue, target: 100}
+	assert.False(t, s.done(now))
+	assert.False(t, s.done(now.Add(settleQuietPeriod/2)))
+	assert.True(t, s.done(now.Add(settleQuietPeriod)))
+}
+
+func TestPingUdevd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control")
+	l, err := net.Listen("unixpacket", path)
+	assert.Nil(t, err)
+	defer l.Close()
+
+	types := make(chan uint32, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		buf := make([]byte, udevCtrlMessageSize)
+		for i := 0; i < 2; i++ {
+			if n, err := conn.Read(buf); err != nil || n != udevCtrlMessageSize ||
+				binary.NativeEndian.Uint32(buf[16:]) != udevCtrlMagic {
+				break
+			}
+			types <- binary.NativeEndian.Uint32(buf[20:])
+		}
+		close(types)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, pingUdevd(ctx, path))
+
+	var got []uint32
+	for typ := range types {
+		got = append(got, typ)
+	}
+	assert.Equal(t, []uint32{udevCtrlPing, udevCtrlEndMessages}, got)
+}
+
+// fakeUdevd answers pings on a control socket in dir and points Settle at it and
+// at a queue file in dir
+func fakeUdevd(t *testing.T, dir string) {
+	control := filepath.Join(dir, "control")
+	l, err := net.Listen("unixpacket", control)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			// close once END is read, which ends the ping
+			buf := make([]byte, udevCtrlMessageSize)
+			for {
+				if _, err := conn.Read(buf); err != nil || binary.NativeEndian.Uint32(buf[20:]) == udevCtrlEndMessages {
+					break
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	queue, controlPath := udevQueuePath, udevControlPath
+	udevQueuePath, udevControlPath = filepath.Join(dir, "queue"), control
+	t.Cleanup(func() { udevQueuePath, udevControlPath = queue, controlPath })
+}
+
+func TestSettleFakeUdevd(t *testing.T) {
+	dir := t.TempDir()
+	fakeUdevd(t, dir)
+
+	c := NewContext()
+	defer c.Free()
+
+	// pinged and the queue is empty
+	assert.Nil(t, c.Settle(context.Background(), time.Second))
+
+	// a busy queue holds Settle until it is gone
+	queue := filepath.Join(dir, "queue")
+	assert.Nil(t, os.WriteFile(queue, nil, 0644))
+	err := c.Settle(context.Background(), 200*time.Millisecond)
+	assert.True(t, errors.Is(err, ErrSettleTimeout))
+
+	time.AfterFunc(200*time.Millisecond, func() { os.Remove(queue) })
+	start := time.Now()
+	assert.Nil(t, c.Settle(context.Background(), 2*time.Second))
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
@@ -0,0 +1,391 @@
+package goudev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	enclosureLinkPrefix = "enclosure_device:"
+)
+
+var (
+	ErrNotScsiDevice      = errors.New("udev: device is not a scsi device")
+	ErrNotEnclosure       = errors.New("udev: device is not an enclosure")
+	ErrNoEnclosureSlot    = errors.New("udev: device is not in an enclosure slot")
+	ErrBadScsiAddressTmpl = "udev: bad scsi address %q"
+)
+
+// ScsiAddress is the host:channel:target:lun of a scsi device
+type ScsiAddress struct {
+	Host    int    `json:"host"`
+	Channel int    `json:"channel"`
+	Target  int    `json:"target"`
+	Lun     uint64 `json:"lun"`
+}
+
+func ParseScsiAddress(s string) (ScsiAddress, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 {
+		return ScsiAddress{}, fmt.Errorf(ErrBadScsiAddressTmpl, s)
+	}
+
+	var a ScsiAddress
+	var err error
+	for i, p := range []*int{&a.Host, &a.Channel, &a.Target} {
+		if *p, err = strconv.Atoi(parts[i]); err != nil {
+			return ScsiAddress{}, fmt.Errorf(ErrBadScsiAddressTmpl, s)
+		}
+	}
+	if a.Lun, err = strconv.ParseUint(parts[3], 10, 64); err != nil {
+		return ScsiAddress{}, fmt.Errorf(ErrBadScsiAddressTmpl, s)
+	}
+
+	return a, nil
+}
+
+func (a ScsiAddress) String() string {
+	return fmt.Sprintf("%d:%d:%d:%d", a.Host, a.Channel, a.Target, a.Lun)
+}
+
+// SasPath is the SAS part of a devpath, e.g.
+// .../host0/port-0:0/expander-0:0/port-0:0:3/end_device-0:0:3/target0:0:3/0:0:3:0
+type SasPath struct {
+	Expanders []string `json:"expanders,omitempty"` // from the HBA outwards
+	Port      string   `json:"port,omitempty"`      // the port of the end device
+	EndDevice string   `json:"end_device,omitempty"`
+}
+
+func ParseSasPath(devpath string) SasPath {
+	var p SasPath
+	for _, c := range strings.Split(devpath, "/") {
+		switch {
+		case strings.HasPrefix(c, "expander-"):
+			p.Expanders = append(p.Expanders, c)
+		case strings.HasPrefix(c, "port-"):
+			p.Port = c
+		case strings.HasPrefix(c, "end_device-"):
+			p.EndDevice = c
+		}
+	}
+
+	return p
+}
+
+// ScsiDevice is a typed view of a scsi_device Device, e.g. 0:0:3:0
+type ScsiDevice struct {
+	*Device
+}
+
+func NewScsiDevice(d *Device) (*ScsiDevice, error) {
+	if d.Subsystem() != "scsi" || d.DeviceType() != "scsi_device" {
+		return nil, ErrNotScsiDevice
+	}
+
+	return &ScsiDevice{d}, nil
+}
+
+// ScsiDeviceOf returns the scsi device of a block device such as sda or sda1
+func ScsiDeviceOf(d *Device) (*ScsiDevice, error) {
+	p, err := d.FindParent("scsi", "scsi_device")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScsiDevice{p}, nil
+}
+
+func (s *ScsiDevice) Address() (ScsiAddress, error) {
+	return ParseScsiAddress(s.SysName())
+}
+
+func (s *ScsiDevice) Vendor() string {
+	return strings.TrimSpace(s.GetAttribute("vendor"))
+}
+
+func (s *ScsiDevice) Model() string {
+	return strings.TrimSpace(s.GetAttribute("model"))
+}
+
+func (s *ScsiDevice) Revision() string {
+	return strings.TrimSpace(s.GetAttribute("rev"))
+}
+
+// Type returns the peripheral device type, 0 for disks and 13 for enclosures
+func (s *ScsiDevice) Type() int {
+	v, _ := s.attributeInt("type")
+	return v
+}
+
+// sasAttribute reads attribute of the sas transport class device below the end device
+func (s *ScsiDevice) sasAttribute(class, attribute string) string {
+	p := ParseSasPath(s.DevicePath())
+	if p.EndDevice == "" {
+		return ""
+	}
+
+	i := strings.Index(s.SysPath(), "/"+p.EndDevice+"/")
+	if i < 0 {
+		return ""
+	}
+	dir := s.SysPath()[:i+1+len(p.EndDevice)]
+
+	data, err := os.ReadFile(filepath.Join(dir, class, p.EndDevice, attribute))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SasAddress returns the SAS address like 0x5000c500a1b2c3d4, "" for non SAS devices
+func (s *ScsiDevice) SasAddress() string {
+	// mpt3sas exposes it on the scsi device, the transport class covers the others
+	if v := strings.TrimSpace(s.GetAttribute("sas_address")); v != "" {
+		return v
+	}
+	return s.sasAttribute("sas_device", "sas_address")
+}
+
+// Phy returns the phy identifier of the expander or HBA the device is attached to, -1 if unknown
+func (s *ScsiDevice) Phy() int {
+	if v, err := strconv.Atoi(s.sasAttribute("sas_device", "phy_identifier")); err == nil {
+		return v
+	}
+	return -1
+}
+
+// Bay returns the bay identifier reported by the expander, -1 if unknown
+func (s *ScsiDevice) Bay() int {
+	if v, err := strconv.Atoi(s.sasAttribute("sas_device", "bay_identifier")); err == nil {
+		return v
+	}
+	return -1
+}
+
+// EnclosureSlot follows the enclosure_device link the ses driver creates
+func (s *ScsiDevice) EnclosureSlot() (*EnclosureSlot, error) {
+	entries, err := os.ReadDir(s.SysPath())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), enclosureLinkPrefix) {
+			continue
+		}
+		p, err := filepath.EvalSymlinks(filepath.Join(s.SysPath(), e.Name()))
+		if err != nil {
+			continue
+		}
+		return &EnclosureSlot{Name: strings.TrimPrefix(e.Name(), enclosureLinkPrefix), path: p}, nil
+	}
+
+	return nil, ErrNoEnclosureSlot
+}
+
+// ScsiLocation is the physical location of a scsi device
+type ScsiLocation struct {
+	Address    ScsiAddress `json:"address"`
+	Vendor     string      `json:"vendor"`
+	Model      string      `json:"model"`
+	SasAddress string      `json:"sas_address,omitempty"`
+	SasPath
+	Phy       int    `json:"phy"`
+	Bay       int    `json:"bay"`
+	Enclosure string `json:"enclosure,omitempty"` // enclosure id (logical identifier)
+	Slot      string `json:"slot,omitempty"`      // component name, e.g. "SLOT 5" or "Disk005"
+	SlotNum   int    `json:"slot_num"`
+}
+
+func (s *ScsiDevice) Location() (*ScsiLocation, error) {
+	a, err := s.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ScsiLocation{
+		Address:    a,
+		Vendor:     s.Vendor(),
+		Model:      s.Model(),
+		SasAddress: s.SasAddress(),
+		SasPath:    ParseSasPath(s.DevicePath()),
+		Phy:        s.Phy(),
+		Bay:        s.Bay(),
+		SlotNum:    -1,
+	}
+
+	if slot, err := s.EnclosureSlot(); err == nil {
+		l.Enclosure = slot.EnclosureID()
+		l.Slot = slot.Name
+		l.SlotNum = slot.Number()
+	}
+
+	return l, nil
+}
+
+// EnclosureSlot is a component of an enclosure class device, e.g.
+// /sys/class/enclosure/0:0:10:0/SLOT 5. It is no Device, only a sysfs directory.
+type EnclosureSlot struct {
+	Name string
+	path string
+}
+
+func (e *EnclosureSlot) Path() string {
+	return e.path
+}
+
+func (e *EnclosureSlot) read(attribute string) string {
+	data, err := os.ReadFile(filepath.Join(e.path, attribute))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func (e *EnclosureSlot) flag(attribute string) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(e.path, attribute))
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(data)) == "1", nil
+}
+
+func (e *EnclosureSlot) setFlag(attribute string, on bool) error {
+	v := "0"
+	if on {
+		v = "1"
+	}
+	return writeSysfs(filepath.Join(e.path, attribute), v)
+}
+
+// EnclosureID returns the id of the enclosure the slot belongs to
+func (e *EnclosureSlot) EnclosureID() string {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(e.path), "id"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Number returns the slot number, -1 if the enclosure does not report it
+func (e *EnclosureSlot) Number() int {
+	if v, err := strconv.Atoi(e.read("slot")); err == nil {
+		return v
+	}
+	return -1
+}
+
+// Status returns the SES element status, e.g. OK, not installed, critical
+func (e *EnclosureSlot) Status() string {
+	return e.read("status")
+}
+
+// Type returns the component type, e.g. array device or device
+func (e *EnclosureSlot) Type() string {
+	return e.read("type")
+}
+
+// Locate reports whether the identify LED is on
+func (e *EnclosureSlot) Locate() (bool, error) {
+	return e.flag("locate")
+}
+
+func (e *EnclosureSlot) SetLocate(on bool) error {
+	return e.setFlag("locate", on)
+}
+
+// Fault reports whether the fault LED is on
+func (e *EnclosureSlot) Fault() (bool, error) {
+	return e.flag("fault")
+}
+
+func (e *EnclosureSlot) SetFault(on bool) error {
+	return e.setFlag("fault", on)
+}
+
+// Device returns the scsi device in the slot, ErrNoParentDevice if it is empty
+func (e *EnclosureSlot) Device(c *Context) (*ScsiDevice, error) {
+	p, err := filepath.EvalSymlinks(filepath.Join(e.path, "device"))
+	if err != nil {
+		return nil, ErrNoParentDevice
+	}
+
+	d, err := Devices.FromSysPath(c, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScsiDevice{d}, nil
+}
+
+// Enclosure is a typed view of an enclosure class Device, created by the ses driver
+type Enclosure struct {
+	*Device
+}
+
+func NewEnclosure(d *Device) (*Enclosure, error) {
+	if d.Subsystem() != "enclosure" {
+		return nil, ErrNotEnclosure
+	}
+
+	return &Enclosure{d}, nil
+}
+
+// ID returns the enclosure logical identifier
+func (e *Enclosure) ID() string {
+	return e.GetAttribute("id")
+}
+
+func enclosureSlots(dir string) ([]*EnclosureSlot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []*EnclosureSlot
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		// components are plain directories with a type attribute
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(p, "type")); err != nil {
+			continue
+		}
+		ss = append(ss, &EnclosureSlot{Name: e.Name(), path: p})
+	}
+	sort.SliceStable(ss, func(i, j int) bool {
+		ni, nj := ss[i].Number(), ss[j].Number()
+		if ni != nj {
+			return ni < nj
+		}
+		return ss[i].Name < ss[j].Name
+	})
+
+	return ss, nil
+}
+
+// Slots returns the components ordered by slot number
+func (e *Enclosure) Slots() ([]*EnclosureSlot, error) {
+	return enclosureSlots(e.SysPath())
+}
+
+// Enclosures returns all enclosures ordered by name
+func (c *Context) Enclosures() ([]*Enclosure, error) {
+	ds, err := c.subsystemDevices("enclosure", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	es := make([]*Enclosure, 0, len(ds))
+	for _, d := range ds {
+		es = append(es, &Enclosure{d})
+	}
+
+	return es, nil
+}
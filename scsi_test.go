@@ -0,0 +1,82 @@
+package goudev
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScsiAddress(t *testing.T) {
+	a, err := ParseScsiAddress("0:0:3:0")
+	assert.Nil(t, err)
+	assert.Equal(t, ScsiAddress{Host: 0, Channel: 0, Target: 3, Lun: 0}, a)
+	assert.Equal(t, "0:0:3:0", a.String())
+
+	_, err = ParseScsiAddress("target0:0:3")
+	assert.NotNil(t, err)
+}
+
+func TestParseSasPath(t *testing.T) {
+	p := ParseSasPath("/devices/pci0000:00/0000:00:01.0/0000:01:00.0/host0/port-0:0/expander-0:0/port-0:0:3/end_device-0:0:3/target0:0:3/0:0:3:0")
+	assert.Equal(t, SasPath{Expanders: []string{"expander-0:0"}, Port: "port-0:0:3", EndDevice: "end_device-0:0:3"}, p)
+
+	p = ParseSasPath("/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0")
+	assert.Equal(t, SasPath{}, p)
+}
+
+func TestEnclosureSlots(t *testing.T) {
+	dir := t.TempDir()
+	for name, slot := range map[string]string{"SLOT 10": "10", "SLOT 2": "2", "SLOT 1": "1"} {
+		p := filepath.Join(dir, name)
+		assert.Nil(t, os.Mkdir(p, 0755))
+		for attr, v := range map[string]string{"type": "array device", "slot": slot, "status": "OK", "locate": "0", "fault": "0"} {
+			assert.Nil(t, os.WriteFile(filepath.Join(p, attr), []byte(v+"\n"), 0644))
+		}
+	}
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "power"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "id"), []byte("0x500605b00ab2c3d4\n"), 0644))
+
+	ss, err := enclosureSlots(dir)
+	assert.Nil(t, err)
+	assert.Len(t, ss, 3)
+	assert.Equal(t, "SLOT 1", ss[0].Name)
+	assert.Equal(t, 10, ss[2].Number())
+	assert.Equal(t, "OK", ss[1].Status())
+	assert.Equal(t, "0x500605b00ab2c3d4", ss[1].EnclosureID())
+
+	assert.Nil(t, ss[1].SetLocate(true))
+	on, err := ss[1].Locate()
+	assert.Nil(t, err)
+	assert.True(t, on)
+
+	on, err = ss[1].Fault()
+	assert.Nil(t, err)
+	assert.False(t, on)
+}
+
+func TestScsiLocation(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	ds, err := ctx.subsystemDevices("scsi_disk", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer FreeDevices(ds)
+	if len(ds) == 0 {
+		t.Skip("no scsi disks")
+	}
+
+	s, err := ScsiDeviceOf(ds[0])
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer s.Free()
+
+	l, err := s.Location()
+	assert.Nil(t, err)
+	spew.Dump(l)
+}
@@ -0,0 +1,197 @@
+package goudev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotSriov           = errors.New("udev: device is not SR-IOV capable")
+	ErrNotVirtualFunction = errors.New("udev: device is not a SR-IOV virtual function")
+	ErrTooManyVFsTmpl     = "udev: %d VFs requested, sriov_totalvfs is %d"
+)
+
+// sriovAttribute reads sysfs directly, the VF count changes under a Device
+// through SetSriovNumVFs or other tools
+func (d *Device) sriovAttribute(attribute string) (int, error) {
+	v := d.readAttribute(attribute)
+	if v == "" {
+		return 0, ErrNotSriov
+	}
+
+	return strconv.Atoi(v)
+}
+
+func (d *Device) SriovTotalVFs() (int, error) {
+	return d.sriovAttribute("sriov_totalvfs")
+}
+
+func (d *Device) SriovNumVFs() (int, error) {
+	return d.sriovAttribute("sriov_numvfs")
+}
+
+// SetSriovNumVFs changes the VF count, going through 0 since the kernel
+// refuses to change a non-zero count directly
+func (d *Device) SetSriovNumVFs(n int) error {
+	total, err := d.SriovTotalVFs()
+	if err != nil {
+		return err
+	}
+	if n < 0 || n > total {
+		return fmt.Errorf(ErrTooManyVFsTmpl, n, total)
+	}
+
+	cur, err := d.SriovNumVFs()
+	if err != nil {
+		return err
+	}
+	if cur == n {
+		return nil
+	}
+	if cur != 0 && n != 0 {
+		if err = d.SetAttribute("sriov_numvfs", "0"); err != nil {
+			return err
+		}
+	}
+
+	return d.SetAttribute("sriov_numvfs", strconv.Itoa(n))
+}
+
+func (d *Device) IsVirtualFunction() bool {
+	_, err := os.Lstat(filepath.Join(d.SysPath(), "physfn"))
+	return err == nil
+}
+
+// PhysicalFunction returns the PF of a VF
+func (d *Device) PhysicalFunction() (*Device, error) {
+	p, err := filepath.EvalSymlinks(filepath.Join(d.SysPath(), "physfn"))
+	if err != nil {
+		return nil, ErrNotVirtualFunction
+	}
+
+	return Devices.FromSysPath(d.context(), p)
+}
+
+// virtfnPaths returns the VF syspaths ordered by VF index
+func (d *Device) virtfnPaths() ([]string, error) {
+	links, err := filepath.Glob(filepath.Join(d.SysPath(), "virtfn*"))
+	if err != nil {
+		return nil, err
+	}
+
+	idx := make(map[string]int, len(links))
+	for _, l := range links {
+		n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(l), "virtfn"))
+		if err != nil {
+			continue
+		}
+		idx[l] = n
+	}
+
+	ps := make([]string, 0, len(idx))
+	for l := range idx {
+		ps = append(ps, l)
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		return idx[ps[i]] < idx[ps[j]]
+	})
+
+	for i := range ps {
+		if ps[i], err = filepath.EvalSymlinks(ps[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return ps, nil
+}
+
+// VirtualFunctions returns the VFs of a PF, ordered by VF index
+func (d *Device) VirtualFunctions() ([]*Device, error) {
+	ps, err := d.virtfnPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	vfs := make([]*Device, 0, len(ps))
+	for _, p := range ps {
+		vf, err := Devices.FromSysPath(d.context(), p)
+		if err != nil {
+			FreeDevices(vfs)
+			return nil, err
+		}
+		vfs = append(vfs, vf)
+	}
+
+	return vfs, nil
+}
+
+// NetDevices returns the net interfaces of a PCI function
+func (d *Device) NetDevices() ([]*Device, error) {
+	return d.Children(func(p *Device) FilterFn {
+		return WithFilterSubsystem("net")
+	})
+}
+
+// WaitForVirtualFunctions waits until sriov_numvfs VFs exist and are initialized, and
+// with withNet until each VF has an initialized net interface (needs a bound net driver).
+func (d *Device) WaitForVirtualFunctions(ctx context.Context, withNet bool) ([]*Device, error) {
+	n, err := d.SriovNumVFs()
+	if err != nil {
+		return nil, err
+	}
+
+	// the virtfn links are created before the uevents, wait for all of them first
+	ticker := time.NewTicker(driverPollInterval)
+	defer ticker.Stop()
+	var ps []string
+	for {
+		if ps, err = d.virtfnPaths(); err != nil {
+			return nil, err
+		}
+		if len(ps) >= n {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	c := d.context()
+	vfs := make([]*Device, 0, n)
+	for _, p := range ps[:n] {
+		vf, err := c.WaitForDevice(ctx, &DeviceQuery{
+			Subsystem: "pci",
+			SysName:   filepath.Base(p),
+		})
+		if err != nil {
+			FreeDevices(vfs)
+			return nil, err
+		}
+		vfs = append(vfs, vf)
+
+		if !withNet {
+			continue
+		}
+		nd, err := c.WaitForDevice(ctx, &DeviceQuery{
+			Subsystem:  "net",
+			Properties: map[string]string{"DEVPATH": vf.DevicePath() + "/net/*"},
+		})
+		if err != nil {
+			FreeDevices(vfs)
+			return nil, err
+		}
+		nd.Free()
+	}
+
+	return vfs, nil
+}
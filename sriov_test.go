@@ -0,0 +1,54 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceSriov(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "pci", "0000:65:00.0")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	total, err := d.SriovTotalVFs()
+	if err == ErrNotSriov {
+		t.Skip(err)
+	}
+	assert.Nil(t, err)
+
+	num, err := d.SriovNumVFs()
+	assert.Nil(t, err)
+	spew.Dump(total, num)
+
+	vfs, err := d.VirtualFunctions()
+	assert.Nil(t, err)
+	assert.Len(t, vfs, num)
+	defer FreeDevices(vfs)
+
+	for _, vf := range vfs {
+		assert.True(t, vf.IsVirtualFunction())
+
+		pf, err := vf.PhysicalFunction()
+		assert.Nil(t, err)
+		assert.Equal(t, d.SysPath(), pf.SysPath())
+		pf.Free()
+	}
+}
+
+func TestDeviceNotSriov(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromSysPath(ctx, "/sys/devices/virtual/mem/null")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	_, err = d.SriovNumVFs()
+	assert.Equal(t, ErrNotSriov, err)
+	assert.Equal(t, ErrNotSriov, d.SetSriovNumVFs(1))
+}
@@ -0,0 +1,159 @@
+package goudev
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidActionTmpl = "udev: invalid uevent action %q"
+	ErrInvalidEnvTmpl    = "udev: invalid uevent env %q, want KEY=VALUE"
+)
+
+// https://github.com/torvalds/linux/blob/master/lib/kobject_uevent.c
+var ueventActions = map[string]bool{
+	"add":     true,
+	"remove":  true,
+	"change":  true,
+	"move":    true,
+	"online":  true,
+	"offline": true,
+	"bind":    true,
+	"unbind":  true,
+}
+
+// NewSynthUUID returns a random uuid for synthetic uevents
+func NewSynthUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// "ACTION [UUID [KEY=VALUE ...]]", see Documentation/ABI/testing/sysfs-uevent
+func formatUevent(action, uuid string, env []string) (string, error) {
+	if !ueventActions[action] {
+		return "", fmt.Errorf(ErrInvalidActionTmpl, action)
+	}
+
+	parts := []string{action}
+	if uuid != "" {
+		parts = append(parts, uuid)
+	} else if len(env) > 0 {
+		return "", errors.New("udev: uevent env requires a synth uuid")
+	}
+	for _, kv := range env {
+		if i := strings.IndexByte(kv, '='); i <= 0 || strings.ContainsAny(kv, " \n") {
+			return "", fmt.Errorf(ErrInvalidEnvTmpl, kv)
+		}
+		parts = append(parts, kv)
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// Trigger writes action to the uevent attribute, like `udevadm trigger --action`
+func (d *Device) Trigger(action string) error {
+	return d.TriggerSynth(action, "")
+}
+
+// TriggerSynth sends a synthetic uevent carrying SYNTH_UUID=uuid and
+// SYNTH_ARG_KEY=VALUE for every env entry
+func (d *Device) TriggerSynth(action, uuid string, env ...string) error {
+	s, err := formatUevent(action, uuid, env)
+	if err != nil {
+		return err
+	}
+
+	return d.SetAttribute("uevent", s)
+}
+
+type TriggerResult struct {
+	// syspaths
+	Triggered []string
+	Failed    map[string]error
+	// triggered, but no processed event was received before ctx was done
+	NotAnswered []string
+}
+
+// Trigger sends action to all enumerated devices. With wait, it returns once udevd has
+// processed every event or ctx is done; devices without answer are listed in NotAnswered.
+func (e *Enumerate) Trigger(ctx context.Context, action string, wait bool, env ...string) (*TriggerResult, error) {
+	if !ueventActions[action] {
+		return nil, fmt.Errorf(ErrInvalidActionTmpl, action)
+	}
+
+	ds, err := e.Devices(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer FreeDevices(ds)
+
+	var ch <-chan *Device
+	if wait && len(ds) > 0 {
+		mctx, cancel := context.WithCancel(ctx)
+
+		m := ds[0].context().NewMonitor()
+		defer m.Free()
+		ch, err = m.DeviceChan(mctx, waitEpollTimeout)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		defer drainDeviceChan(ch)
+		defer cancel()
+	}
+
+	r := &TriggerResult{
+		Failed: map[string]error{},
+	}
+	pending := map[string]string{} // uuid -> syspath
+	for _, d := range ds {
+		uuid, err := NewSynthUUID()
+		if err != nil {
+			return nil, err
+		}
+		if err := d.TriggerSynth(action, uuid, env...); err != nil {
+			r.Failed[d.SysPath()] = err
+			continue
+		}
+
+		r.Triggered = append(r.Triggered, d.SysPath())
+		pending[uuid] = d.SysPath()
+	}
+
+	for wait && len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			wait = false
+		case d, ok := <-ch:
+			if !ok {
+				wait = false
+				continue
+			}
+
+			delete(pending, d.Get("SYNTH_UUID"))
+			d.Free()
+		}
+	}
+	if ch != nil && len(pending) > 0 {
+		missing := make(map[string]bool, len(pending))
+		for _, syspath := range pending {
+			missing[syspath] = true
+		}
+		for _, syspath := range r.Triggered {
+			if missing[syspath] {
+				r.NotAnswered = append(r.NotAnswered, syspath)
+			}
+		}
+	}
+
+	return r, nil
+}
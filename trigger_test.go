@@ -0,0 +1,72 @@
+package goudev
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatUevent(t *testing.T) {
+	s, err := formatUevent("change", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "change", s)
+
+	uuid, err := NewSynthUUID()
+	assert.Nil(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), uuid)
+
+	s, err = formatUevent("add", uuid, []string{"FOO=1", "BAR=x"})
+	assert.Nil(t, err)
+	assert.Equal(t, "add "+uuid+" FOO=1 BAR=x", s)
+
+	_, err = formatUevent("poke", "", nil)
+	assert.NotNil(t, err)
+
+	_, err = formatUevent("change", "", []string{"FOO=1"})
+	assert.NotNil(t, err)
+
+	_, err = formatUevent("change", uuid, []string{"FOO"})
+	assert.NotNil(t, err)
+}
+
+func TestEnumerateTrigger(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("writing uevent needs root")
+	}
+
+	ctx := NewContext()
+	defer ctx.Free()
+
+	e := ctx.NewEnumerate()
+	defer e.Free()
+	assert.Nil(t, e.MatchSubsystem("mem"))
+	assert.Nil(t, e.MatchSysname("null"))
+
+	_, err := e.Trigger(context.Background(), "poke", true)
+	assert.NotNil(t, err)
+
+	cctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	r, err := e.Trigger(cctx, "change", true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/sys/devices/virtual/mem/null"}, r.Triggered)
+	assert.Empty(t, r.Failed)
+
+	// the answer is matched by SYNTH_UUID, without udevd there is none
+	if _, err := os.Stat(udevControlPath); err == nil {
+		assert.Empty(t, r.NotAnswered)
+	} else {
+		assert.Equal(t, r.Triggered, r.NotAnswered)
+	}
+
+	// without wait nothing is reported as unanswered
+	r, err = e.Trigger(context.Background(), "change", false)
+	assert.Nil(t, err)
+	assert.Len(t, r.Triggered, 1)
+	assert.Empty(t, r.NotAnswered)
+}
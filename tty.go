@@ -0,0 +1,159 @@
+package goudev
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	serialByIDDir   = "/dev/serial/by-id/"
+	serialByPathDir = "/dev/serial/by-path/"
+)
+
+var (
+	ErrNotTtyDevice = errors.New("udev: device is not in the tty subsystem")
+)
+
+// TtyDevice is a typed view of a tty subsystem Device
+type TtyDevice struct {
+	*Device
+}
+
+func NewTtyDevice(d *Device) (*TtyDevice, error) {
+	if d.Subsystem() != "tty" {
+		return nil, ErrNotTtyDevice
+	}
+
+	return &TtyDevice{d}, nil
+}
+
+func (t *TtyDevice) links(prefix string) []string {
+	var ls []string
+	for l := range t.DeviceLinks() {
+		if strings.HasPrefix(l, prefix) {
+			ls = append(ls, l)
+		}
+	}
+	sort.Strings(ls)
+	return ls
+}
+
+// ByIDLinks returns the /dev/serial/by-id links
+func (t *TtyDevice) ByIDLinks() []string {
+	return t.links(serialByIDDir)
+}
+
+// ByPathLinks returns the /dev/serial/by-path links
+func (t *TtyDevice) ByPathLinks() []string {
+	return t.links(serialByPathDir)
+}
+
+// Driver returns the driver of the port, e.g. ftdi_sio, cp210x, cdc_acm or serial;
+// the serial-base port driver of newer kernels is skipped
+func (t *TtyDevice) Driver() string {
+	if v := t.Get("ID_USB_DRIVER"); v != "" {
+		return v
+	}
+
+	cur := t.Device
+	for {
+		p, err := cur.Parent()
+		if cur != t.Device {
+			cur.Free()
+		}
+		if err != nil {
+			return ""
+		}
+		cur = p
+
+		if driver := cur.Driver(); driver != "" && cur.Subsystem() != "serial-base" {
+			cur.Free()
+			return driver
+		}
+	}
+}
+
+// IsHardware tells real ports from virtual consoles, ptys and 8250 ports without a UART
+func (t *TtyDevice) IsHardware() bool {
+	if _, err := os.Stat(filepath.Join(t.SysPath(), "device")); err != nil {
+		return false
+	}
+
+	// PORT_UNKNOWN, the 8250 driver registers ports whether or not they exist
+	if v := strings.TrimSpace(t.GetAttribute("type")); v == "0" {
+		return false
+	}
+
+	return true
+}
+
+func WithFilterHardwareTty() FilterFn {
+	return func(td *Device) bool {
+		return (&TtyDevice{td}).IsHardware()
+	}
+}
+
+func (t *TtyDevice) IsUsb() bool {
+	_, err := os.Stat(filepath.Join(t.SysPath(), "device"))
+	return err == nil && strings.Contains(t.DevicePath(), "/usb")
+}
+
+// UsbInterfaceNumber returns bInterfaceNumber of the USB interface the port belongs to
+func (t *TtyDevice) UsbInterfaceNumber() (int, error) {
+	i, err := UsbInterfaceOf(t.Device)
+	if err != nil {
+		return 0, err
+	}
+	defer i.Free()
+
+	return int(i.InterfaceNumber()), nil
+}
+
+// SerialAdapter identifies the USB-serial adapter of a port
+type SerialAdapter struct {
+	VendorID     uint16
+	ProductID    uint16
+	Manufacturer string
+	Product      string
+	Serial       string
+	Interface    int
+	Driver       string
+}
+
+func (t *TtyDevice) Adapter() (*SerialAdapter, error) {
+	u, err := UsbDeviceOf(t.Device)
+	if err != nil {
+		return nil, err
+	}
+	defer u.Free()
+
+	a := &SerialAdapter{
+		VendorID:     u.VendorID(),
+		ProductID:    u.ProductID(),
+		Manufacturer: u.Manufacturer(),
+		Product:      u.Product(),
+		Serial:       u.Serial(),
+		Driver:       t.Driver(),
+	}
+	a.Interface, _ = t.UsbInterfaceNumber()
+
+	return a, nil
+}
+
+// SerialPorts returns the hardware tty ports of the host ordered by name
+func (c *Context) SerialPorts() ([]*TtyDevice, error) {
+	ds, err := c.subsystemDevices("tty", WithFilterHardwareTty())
+	if err != nil {
+		return nil, err
+	}
+
+	ts := make([]*TtyDevice, 0, len(ds))
+	for _, d := range ds {
+		ts = append(ts, &TtyDevice{d})
+	}
+
+	return ts, nil
+}
@@ -0,0 +1,42 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTtyVirtualConsole(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	d, err := Devices.FromName(ctx, "tty", "tty0")
+	assert.Nil(t, err)
+	defer d.Free()
+
+	tty, err := NewTtyDevice(d)
+	assert.Nil(t, err)
+	assert.False(t, tty.IsHardware())
+	assert.False(t, tty.IsUsb())
+}
+
+func TestSerialPorts(t *testing.T) {
+	ctx := NewContext()
+	defer ctx.Free()
+
+	ts, err := ctx.SerialPorts()
+	assert.Nil(t, err)
+
+	for _, tty := range ts {
+		assert.True(t, tty.IsHardware())
+		spew.Dump(tty.DeviceNode(), tty.Driver(), tty.ByIDLinks(), tty.ByPathLinks())
+
+		if tty.IsUsb() {
+			a, err := tty.Adapter()
+			assert.Nil(t, err)
+			spew.Dump(a)
+		}
+		tty.Free()
+	}
+}
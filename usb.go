@@ -0,0 +1,395 @@
+package goudev
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// bDescriptorType, https://www.usb.org/document-library/usb-20-specification ch 9.4
+const (
+	usbDtDevice    = 0x01
+	usbDtConfig    = 0x02
+	usbDtInterface = 0x04
+	usbDtEndpoint  = 0x05
+)
+
+var (
+	ErrNotUsbDevice      = errors.New("udev: device is not a usb_device")
+	ErrNotUsbInterface   = errors.New("udev: device is not a usb_interface")
+	ErrUsbDescriptorTmpl = "udev: bad usb descriptor at offset %d"
+)
+
+// https://www.usb.org/defined-class-codes
+var usbClassNames = map[uint8]string{
+	0x00: "Per Interface",
+	0x01: "Audio",
+	0x02: "Communications",
+	0x03: "HID",
+	0x05: "Physical",
+	0x06: "Image",
+	0x07: "Printer",
+	0x08: "Mass Storage",
+	0x09: "Hub",
+	0x0a: "CDC Data",
+	0x0b: "Smart Card",
+	0x0d: "Content Security",
+	0x0e: "Video",
+	0x0f: "Personal Healthcare",
+	0x10: "Audio/Video",
+	0x11: "Billboard",
+	0x12: "Type-C Bridge",
+	0xdc: "Diagnostic",
+	0xe0: "Wireless",
+	0xef: "Miscellaneous",
+	0xfe: "Application Specific",
+	0xff: "Vendor Specific",
+}
+
+func UsbClassName(class uint8) string {
+	if s, ok := usbClassNames[class]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown (%02x)", class)
+}
+
+type UsbDeviceDescriptor struct {
+	BcdUSB            uint16
+	Class             uint8
+	SubClass          uint8
+	Protocol          uint8
+	MaxPacketSize0    uint8
+	VendorID          uint16
+	ProductID         uint16
+	BcdDevice         uint16
+	IManufacturer     uint8
+	IProduct          uint8
+	ISerialNumber     uint8
+	NumConfigurations uint8
+}
+
+type UsbEndpointDescriptor struct {
+	Address       uint8
+	Attributes    uint8
+	MaxPacketSize uint16
+	Interval      uint8
+}
+
+type UsbInterfaceDescriptor struct {
+	InterfaceNumber  uint8
+	AlternateSetting uint8
+	Class            uint8
+	SubClass         uint8
+	Protocol         uint8
+	IInterface       uint8
+	Endpoints        []UsbEndpointDescriptor
+}
+
+type UsbConfigDescriptor struct {
+	ConfigurationValue uint8
+	NumInterfaces      uint8
+	Attributes         uint8
+	MaxPower           int // mA, in 2mA units for USB 2 and 8mA for USB 3 on the wire
+	Interfaces         []UsbInterfaceDescriptor
+}
+
+type UsbDescriptors struct {
+	Device  UsbDeviceDescriptor
+	Configs []UsbConfigDescriptor
+}
+
+// ParseUsbDescriptors parses the binary sysfs descriptors attribute: the device
+// descriptor followed by the raw configuration descriptors. Class specific
+// descriptors are skipped.
+func ParseUsbDescriptors(data []byte) (*UsbDescriptors, error) {
+	ds := &UsbDescriptors{}
+
+	superSpeed := false
+	var cfg *UsbConfigDescriptor
+	var intf *UsbInterfaceDescriptor
+	for off := 0; off < len(data); {
+		if off+2 > len(data) || data[off] < 2 || off+int(data[off]) > len(data) {
+			return nil, fmt.Errorf(ErrUsbDescriptorTmpl, off)
+		}
+		b := data[off : off+int(data[off])]
+
+		switch b[1] {
+		case usbDtDevice:
+			if off != 0 || len(b) < 18 {
+				return nil, fmt.Errorf(ErrUsbDescriptorTmpl, off)
+			}
+			ds.Device = UsbDeviceDescriptor{
+				BcdUSB:            binary.LittleEndian.Uint16(b[2:]),
+				Class:             b[4],
+				SubClass:          b[5],
+				Protocol:          b[6],
+				MaxPacketSize0:    b[7],
+				VendorID:          binary.LittleEndian.Uint16(b[8:]),
+				ProductID:         binary.LittleEndian.Uint16(b[10:]),
+				BcdDevice:         binary.LittleEndian.Uint16(b[12:]),
+				IManufacturer:     b[14],
+				IProduct:          b[15],
+				ISerialNumber:     b[16],
+				NumConfigurations: b[17],
+			}
+			superSpeed = ds.Device.BcdUSB >= 0x0300
+		case usbDtConfig:
+			if len(b) < 9 {
+				return nil, fmt.Errorf(ErrUsbDescriptorTmpl, off)
+			}
+			unit := 2
+			if superSpeed {
+				unit = 8
+			}
+			ds.Configs = append(ds.Configs, UsbConfigDescriptor{
+				NumInterfaces:      b[4],
+				ConfigurationValue: b[5],
+				Attributes:         b[7],
+				MaxPower:           int(b[8]) * unit,
+			})
+			cfg, intf = &ds.Configs[len(ds.Configs)-1], nil
+		case usbDtInterface:
+			if len(b) < 9 || cfg == nil {
+				return nil, fmt.Errorf(ErrUsbDescriptorTmpl, off)
+			}
+			cfg.Interfaces = append(cfg.Interfaces, UsbInterfaceDescriptor{
+				InterfaceNumber:  b[2],
+				AlternateSetting: b[3],
+				Class:            b[5],
+				SubClass:         b[6],
+				Protocol:         b[7],
+				IInterface:       b[8],
+			})
+			intf = &cfg.Interfaces[len(cfg.Interfaces)-1]
+		case usbDtEndpoint:
+			if len(b) < 7 || intf == nil {
+				return nil, fmt.Errorf(ErrUsbDescriptorTmpl, off)
+			}
+			intf.Endpoints = append(intf.Endpoints, UsbEndpointDescriptor{
+				Address:       b[2],
+				Attributes:    b[3],
+				MaxPacketSize: binary.LittleEndian.Uint16(b[4:]),
+				Interval:      b[6],
+			})
+		}
+
+		off += len(b)
+	}
+
+	return ds, nil
+}
+
+func (d *Device) attributeHex16(attribute string) uint16 {
+	v, _ := strconv.ParseUint(strings.TrimSpace(d.GetAttribute(attribute)), 16, 16)
+	return uint16(v)
+}
+
+func (d *Device) attributeHex8(attribute string) uint8 {
+	v, _ := strconv.ParseUint(strings.TrimSpace(d.GetAttribute(attribute)), 16, 8)
+	return uint8(v)
+}
+
+// UsbDevice is a typed view of a usb Device with devtype usb_device
+type UsbDevice struct {
+	*Device
+}
+
+func NewUsbDevice(d *Device) (*UsbDevice, error) {
+	if d.Subsystem() != "usb" || d.DeviceType() != "usb_device" {
+		return nil, ErrNotUsbDevice
+	}
+
+	return &UsbDevice{d}, nil
+}
+
+// UsbDeviceOf returns the usb_device d (tty, block, net, hidraw, ...) belongs to
+func UsbDeviceOf(d *Device) (*UsbDevice, error) {
+	p, err := d.FindParent("usb", "usb_device")
+	if err != nil {
+		return nil, err
+	}
+
+	return &UsbDevice{p}, nil
+}
+
+func (u *UsbDevice) VendorID() uint16 {
+	return u.attributeHex16("idVendor")
+}
+
+func (u *UsbDevice) ProductID() uint16 {
+	return u.attributeHex16("idProduct")
+}
+
+func (u *UsbDevice) BcdDevice() uint16 {
+	return u.attributeHex16("bcdDevice")
+}
+
+// Version is the USB spec version, e.g. " 2.00"
+func (u *UsbDevice) Version() string {
+	return strings.TrimSpace(u.GetAttribute("version"))
+}
+
+func (u *UsbDevice) Class() uint8 {
+	return u.attributeHex8("bDeviceClass")
+}
+
+func (u *UsbDevice) Manufacturer() string {
+	return strings.TrimSpace(u.GetAttribute("manufacturer"))
+}
+
+func (u *UsbDevice) Product() string {
+	return strings.TrimSpace(u.GetAttribute("product"))
+}
+
+func (u *UsbDevice) Serial() string {
+	return strings.TrimSpace(u.GetAttribute("serial"))
+}
+
+// Speed in Mbit/s: 1.5, 12, 480, 5000, 10000, 20000
+func (u *UsbDevice) Speed() float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSpace(u.GetAttribute("speed")), 64)
+	return v
+}
+
+func (u *UsbDevice) BusNum() int {
+	n, _ := u.attributeInt("busnum")
+	return n
+}
+
+func (u *UsbDevice) DevNum() int {
+	n, _ := u.attributeInt("devnum")
+	return n
+}
+
+// PortPath is the port chain from the root hub, e.g. "1.2" for 1-1.2, empty for root hubs
+func (u *UsbDevice) PortPath() string {
+	return strings.TrimSpace(u.GetAttribute("devpath"))
+}
+
+func (u *UsbDevice) IsRootHub() bool {
+	return strings.HasPrefix(u.SysName(), "usb")
+}
+
+// Configuration is the active bConfigurationValue, 0 if unconfigured
+func (u *UsbDevice) Configuration() int {
+	n, _ := u.attributeInt("bConfigurationValue")
+	return n
+}
+
+func (u *UsbDevice) NumInterfaces() int {
+	n, _ := u.attributeInt("bNumInterfaces")
+	return n
+}
+
+// Descriptors reads the binary descriptors attribute, which GetAttribute would cut at the first NUL
+func (u *UsbDevice) Descriptors() (*UsbDescriptors, error) {
+	data, err := os.ReadFile(filepath.Join(u.SysPath(), "descriptors"))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseUsbDescriptors(data)
+}
+
+// Interfaces returns the interfaces of the active configuration
+func (u *UsbDevice) Interfaces() ([]*UsbInterface, error) {
+	ds, err := u.Children(func(p *Device) FilterFn {
+		return func(td *Device) bool {
+			// children of nested hubs are in the subtree too
+			return td.DeviceType() == "usb_interface" && filepath.Dir(td.SysPath()) == p.SysPath()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	is := make([]*UsbInterface, 0, len(ds))
+	for _, d := range ds {
+		is = append(is, &UsbInterface{d})
+	}
+
+	return is, nil
+}
+
+// UsbInterface is a typed view of a usb Device with devtype usb_interface
+type UsbInterface struct {
+	*Device
+}
+
+func NewUsbInterface(d *Device) (*UsbInterface, error) {
+	if d.Subsystem() != "usb" || d.DeviceType() != "usb_interface" {
+		return nil, ErrNotUsbInterface
+	}
+
+	return &UsbInterface{d}, nil
+}
+
+// UsbInterfaceOf returns the usb_interface d (tty, block, net, hidraw, ...) belongs to
+func UsbInterfaceOf(d *Device) (*UsbInterface, error) {
+	p, err := d.FindParent("usb", "usb_interface")
+	if err != nil {
+		return nil, err
+	}
+
+	return &UsbInterface{p}, nil
+}
+
+func (i *UsbInterface) InterfaceNumber() uint8 {
+	return i.attributeHex8("bInterfaceNumber")
+}
+
+func (i *UsbInterface) AlternateSetting() uint8 {
+	v, _ := i.attributeInt("bAlternateSetting")
+	return uint8(v)
+}
+
+func (i *UsbInterface) Class() uint8 {
+	return i.attributeHex8("bInterfaceClass")
+}
+
+func (i *UsbInterface) SubClass() uint8 {
+	return i.attributeHex8("bInterfaceSubClass")
+}
+
+func (i *UsbInterface) Protocol() uint8 {
+	return i.attributeHex8("bInterfaceProtocol")
+}
+
+func (i *UsbInterface) NumEndpoints() uint8 {
+	return i.attributeHex8("bNumEndpoints")
+}
+
+// Description is the interface string descriptor, if any
+func (i *UsbInterface) Description() string {
+	return strings.TrimSpace(i.GetAttribute("interface"))
+}
+
+func (i *UsbInterface) UsbDevice() (*UsbDevice, error) {
+	return UsbDeviceOf(i.Device)
+}
+
+// Functions returns the devices provided by the interface, e.g. tty, block, net or hidraw;
+// without subsystems all of them
+func (i *UsbInterface) Functions(subsystems ...string) ([]*Device, error) {
+	return i.Children(func(p *Device) FilterFn {
+		return func(td *Device) bool {
+			if td.Subsystem() == "usb" || (td.DeviceNode() == "" && td.Subsystem() != "net") {
+				return false
+			}
+			if len(subsystems) == 0 {
+				return true
+			}
+			for _, s := range subsystems {
+				if td.Subsystem() == s {
+					return true
+				}
+			}
+			return false
+		}
+	})
+}
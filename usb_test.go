@@ -0,0 +1,48 @@
+package goudev
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// FTDI FT232R: one vendor specific interface with two bulk endpoints
+var ft232Descriptors = []byte{
+	0x12, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x08, 0x03, 0x04, 0x01, 0x60, 0x00, 0x06, 0x01, 0x02, 0x03, 0x01,
+	0x09, 0x02, 0x20, 0x00, 0x01, 0x01, 0x00, 0xa0, 0x2d,
+	0x09, 0x04, 0x00, 0x00, 0x02, 0xff, 0xff, 0xff, 0x02,
+	0x05, 0x24, 0x00, 0x10, 0x01, // class specific, skipped
+	0x07, 0x05, 0x81, 0x02, 0x40, 0x00, 0x00,
+	0x07, 0x05, 0x02, 0x02, 0x40, 0x00, 0x00,
+}
+
+func TestParseUsbDescriptors(t *testing.T) {
+	ds, err := ParseUsbDescriptors(ft232Descriptors)
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint16(0x0200), ds.Device.BcdUSB)
+	assert.Equal(t, uint16(0x0403), ds.Device.VendorID)
+	assert.Equal(t, uint16(0x6001), ds.Device.ProductID)
+	assert.Equal(t, uint16(0x0600), ds.Device.BcdDevice)
+	assert.Equal(t, uint8(1), ds.Device.NumConfigurations)
+
+	assert.Len(t, ds.Configs, 1)
+	assert.Equal(t, uint8(1), ds.Configs[0].ConfigurationValue)
+	assert.Equal(t, 90, ds.Configs[0].MaxPower)
+
+	assert.Len(t, ds.Configs[0].Interfaces, 1)
+	intf := ds.Configs[0].Interfaces[0]
+	assert.Equal(t, uint8(0xff), intf.Class)
+	assert.Equal(t, "Vendor Specific", UsbClassName(intf.Class))
+	assert.Len(t, intf.Endpoints, 2)
+	assert.Equal(t, uint8(0x81), intf.Endpoints[0].Address)
+	assert.Equal(t, uint16(64), intf.Endpoints[1].MaxPacketSize)
+}
+
+func TestParseUsbDescriptorsTruncated(t *testing.T) {
+	_, err := ParseUsbDescriptors(ft232Descriptors[:30])
+	assert.NotNil(t, err)
+
+	_, err = ParseUsbDescriptors([]byte{0x00, 0x01})
+	assert.NotNil(t, err)
+}
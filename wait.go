@@ -0,0 +1,239 @@
+package goudev
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+)
+
+const (
+	waitEpollTimeout = 100 // ms
+)
+
+var (
+	ErrMonitorClosed = errors.New("udev: monitor closed")
+)
+
+// DeviceQuery describes the device to wait for. Empty fields are ignored,
+// values of Properties and Sysattrs may be fnmatch-style patterns like in
+// udev_enumerate_add_match_property/udev_enumerate_add_match_sysattr.
+type DeviceQuery struct {
+	Subsystem  string
+	DeviceType string
+	SysName    string
+	Tag        string
+	// devnode or devlink, e.g. /dev/disk/by-id/X
+	DeviceNode string
+	Properties map[string]string
+	Sysattrs   map[string]string
+}
+
+func matchValue(pattern, value string) bool {
+	if pattern == value {
+		return true
+	}
+
+	ok, _ := filepath.Match(pattern, value)
+	return ok
+}
+
+func (q *DeviceQuery) Match(d *Device) bool {
+	if q.Subsystem != "" && d.Subsystem() != q.Subsystem {
+		return false
+	}
+	if q.DeviceType != "" && d.DeviceType() != q.DeviceType {
+		return false
+	}
+	if q.SysName != "" && !matchValue(q.SysName, d.SysName()) {
+		return false
+	}
+	if q.Tag != "" && !d.HasTag(q.Tag) {
+		return false
+	}
+	if q.DeviceNode != "" && d.DeviceNode() != q.DeviceNode {
+		if _, ok := d.DeviceLinks()[q.DeviceNode]; !ok {
+			return false
+		}
+	}
+	for k, v := range q.Properties {
+		if !matchValue(v, d.Get(k)) {
+			return false
+		}
+	}
+	for k, v := range q.Sysattrs {
+		if !matchValue(v, d.GetAttribute(k)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (q *DeviceQuery) enumerate(c *Context) (ds []*Device, err error) {
+	e := c.NewEnumerate()
+	defer e.Free()
+
+	if q.Subsystem != "" {
+		if err = e.MatchSubsystem(q.Subsystem); err != nil {
+			return nil, err
+		}
+	}
+	if q.SysName != "" {
+		if err = e.MatchSysname(q.SysName); err != nil {
+			return nil, err
+		}
+	}
+	if q.Tag != "" {
+		if err = e.MatchTag(q.Tag); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range q.Properties {
+		if err = e.MatchProperty(k, v); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range q.Sysattrs {
+		if err = e.MatchSysattr(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.Devices(q.Match)
+}
+
+// the monitor must be listening before enumerating, otherwise an event
+// between the scan and the subscription is lost (coldplug race)
+func (q *DeviceQuery) monitor(ctx context.Context, c *Context) (*Monitor, <-chan *Device, error) {
+	m := c.NewMonitor()
+
+	if q.Subsystem != "" {
+		var err error
+		if q.DeviceType != "" {
+			err = m.FilterBy(q.Subsystem, q.DeviceType)
+		} else {
+			err = m.FilterBy(q.Subsystem)
+		}
+		if err != nil {
+			m.Free()
+			return nil, nil, err
+		}
+	}
+	if q.Tag != "" {
+		if err := m.FilterByTag(q.Tag); err != nil {
+			m.Free()
+			return nil, nil, err
+		}
+	}
+
+	ch, err := m.DeviceChan(ctx, waitEpollTimeout)
+	if err != nil {
+		m.Free()
+		return nil, nil, err
+	}
+
+	return m, ch, nil
+}
+
+func drainDeviceChan(ch <-chan *Device) {
+	for d := range ch {
+		d.Free()
+	}
+}
+
+// WaitForDevice returns the first initialized device matching q, including
+// one that already exists. Use ctx for timeout/cancel.
+func (c *Context) WaitForDevice(ctx context.Context, q *DeviceQuery) (*Device, error) {
+	mctx, cancel := context.WithCancel(ctx)
+	m, ch, err := q.monitor(mctx, c)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	defer m.Free()
+	defer drainDeviceChan(ch)
+	defer cancel()
+
+	ds, err := q.enumerate(c)
+	if err != nil {
+		return nil, err
+	}
+	var found *Device
+	for _, d := range ds {
+		if found == nil && d.IsInitialized() {
+			found = d
+			continue
+		}
+		d.Free()
+	}
+	if found != nil {
+		return found, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case d, ok := <-ch:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return nil, ErrMonitorClosed
+			}
+
+			if d.Action() != "remove" && d.IsInitialized() && q.Match(d) {
+				return d, nil
+			}
+			d.Free()
+		}
+	}
+}
+
+// WaitForRemoval returns once no device matches q, including when none
+// matched to begin with.
+func (c *Context) WaitForRemoval(ctx context.Context, q *DeviceQuery) error {
+	mctx, cancel := context.WithCancel(ctx)
+	m, ch, err := q.monitor(mctx, c)
+	if err != nil {
+		cancel()
+		return err
+	}
+	defer m.Free()
+	defer drainDeviceChan(ch)
+	defer cancel()
+
+	ds, err := q.enumerate(c)
+	if err != nil {
+		return err
+	}
+	pending := make(map[string]struct{}, len(ds))
+	for _, d := range ds {
+		pending[d.SysPath()] = struct{}{}
+	}
+	FreeDevices(ds)
+
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-ch:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return ErrMonitorClosed
+			}
+
+			// the device may stop matching (e.g. a changed property) without being removed
+			if d.Action() == "remove" || !q.Match(d) {
+				delete(pending, d.SysPath())
+			} else {
+				pending[d.SysPath()] = struct{}{}
+			}
+			d.Free()
+		}
+	}
+
+	return nil
+}
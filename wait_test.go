@@ -0,0 +1,55 @@
+package goudev
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForDeviceExisting(t *testing.T) {
+	c := NewContext()
+	defer c.Free()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	d, err := c.WaitForDevice(ctx, &DeviceQuery{
+		Subsystem: "block",
+		SysName:   "nvme0n1",
+	})
+	assert.Nil(t, err)
+	defer d.Free()
+
+	spew.Dump(d.String())
+}
+
+func TestWaitForDeviceTimeout(t *testing.T) {
+	c := NewContext()
+	defer c.Free()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	d, err := c.WaitForDevice(ctx, &DeviceQuery{
+		Subsystem: "net",
+		Sysattrs:  map[string]string{"address": "00:00:5e:00:53:ff"},
+	})
+	assert.Nil(t, d)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestWaitForRemovalMissing(t *testing.T) {
+	c := NewContext()
+	defer c.Free()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := c.WaitForRemoval(ctx, &DeviceQuery{
+		DeviceNode: "/dev/disk/by-id/goudev-not-exist",
+	})
+	assert.Nil(t, err)
+}